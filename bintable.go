@@ -0,0 +1,471 @@
+package creditcard
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BINEntry is one range of a BIN table, as read from a CSV or JSON file
+type BINEntry struct {
+	Line    int
+	Company Company
+	Range   IINRange
+	Lengths []int
+}
+
+// BINTableError reports an invalid entry of a BIN table and the line it was found on
+type BINTableError struct {
+	Line int
+	Msg  string
+}
+
+func (e *BINTableError) Error() string {
+	return fmt.Sprintf("BIN table line %d: %s", e.Line, e.Msg)
+}
+
+// binRecord is the JSON representation of a BIN table entry
+type binRecord struct {
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Short   string `json:"short"`
+	Long    string `json:"long"`
+	Lengths []int  `json:"lengths"`
+	Issuer  string `json:"issuer"`
+	Country string `json:"country"`
+	Type    string `json:"type"`
+}
+
+func (rec binRecord) entry(line int) (BINEntry, error) {
+	if rec.Start == "" || rec.End == "" {
+		return BINEntry{}, &BINTableError{line, "start and end are required"}
+	}
+
+	if len(rec.Start) != len(rec.End) {
+		return BINEntry{}, &BINTableError{line, fmt.Sprintf("start %q and end %q have different lengths", rec.Start, rec.End)}
+	}
+
+	if len(rec.Start) > maxPrefixDigits {
+		return BINEntry{}, &BINTableError{line, fmt.Sprintf("range %s-%s is longer than %d digits", rec.Start, rec.End, maxPrefixDigits)}
+	}
+
+	low, err := parseDigits(rec.Start)
+	if err != nil {
+		return BINEntry{}, &BINTableError{line, fmt.Sprintf("start %q is not a number", rec.Start)}
+	}
+
+	high, err := parseDigits(rec.End)
+	if err != nil {
+		return BINEntry{}, &BINTableError{line, fmt.Sprintf("end %q is not a number", rec.End)}
+	}
+
+	if low > high {
+		return BINEntry{}, &BINTableError{line, fmt.Sprintf("range %s-%s is inverted", rec.Start, rec.End)}
+	}
+
+	if rec.Short == "" {
+		return BINEntry{}, &BINTableError{line, "brand short name is required"}
+	}
+
+	if rec.Long == "" {
+		rec.Long = rec.Short
+	}
+
+	for _, l := range rec.Lengths {
+		if l < 1 || l > 19 {
+			return BINEntry{}, &BINTableError{line, fmt.Sprintf("invalid length %d", l)}
+		}
+	}
+
+	return BINEntry{
+		Line:    line,
+		Company: Company{rec.Short, rec.Long},
+		Range: IINRange{
+			Digits:  len(rec.Start),
+			Low:     low,
+			High:    high,
+			Issuer:  rec.Issuer,
+			Country: rec.Country,
+			Type:    rec.Type,
+		},
+		Lengths: rec.Lengths,
+	}, nil
+}
+
+// ParseBINCSV reads a BIN table from CSV. The first row is a header naming
+// the columns: start, end and short are required, long, lengths, issuer,
+// country and type are optional. Lengths are separated by semicolons and
+// may be given as ranges, e.g. "16;18-19".
+func ParseBINCSV(r io.Reader) ([]BINEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &BINTableError{1, "missing header"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"start", "end", "short"} {
+		if _, ok := columns[name]; !ok {
+			return nil, &BINTableError{1, fmt.Sprintf("missing %q column", name)}
+		}
+	}
+
+	var entries []BINEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		lengths, err := parseLengths(field("lengths"))
+		if err != nil {
+			return nil, &BINTableError{line, err.Error()}
+		}
+
+		entry, err := binRecord{
+			Start:   field("start"),
+			End:     field("end"),
+			Short:   field("short"),
+			Long:    field("long"),
+			Lengths: lengths,
+			Issuer:  field("issuer"),
+			Country: field("country"),
+			Type:    field("type"),
+		}.entry(line)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, checkOverlaps(entries)
+}
+
+func parseLengths(s string) ([]int, error) {
+	var lengths []int
+	if s == "" {
+		return lengths, nil
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+
+		min, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid lengths %q", s)
+		}
+
+		max := min
+		if len(bounds) == 2 {
+			max, err = strconv.Atoi(bounds[1])
+			if err != nil || max < min {
+				return nil, fmt.Errorf("invalid lengths %q", s)
+			}
+		}
+
+		for l := min; l <= max; l++ {
+			lengths = append(lengths, l)
+		}
+	}
+
+	return lengths, nil
+}
+
+// ParseBINJSON reads a BIN table from a JSON array of objects with the keys
+// start, end, short, long, lengths, issuer, country and type
+func ParseBINJSON(r io.Reader) ([]BINEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('[') {
+		return nil, &BINTableError{lineAt(decoder.InputOffset()), "expected an array of ranges"}
+	}
+
+	var entries []BINEntry
+	for decoder.More() {
+		// skip to the opening brace so errors point at the entry's first line
+		start := decoder.InputOffset()
+		for start < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+			start++
+		}
+
+		var rec binRecord
+		if err := decoder.Decode(&rec); err != nil {
+			return nil, &BINTableError{lineAt(start), err.Error()}
+		}
+
+		entry, err := rec.entry(lineAt(start))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, &BINTableError{lineAt(decoder.InputOffset()), err.Error()}
+	}
+
+	return entries, checkOverlaps(entries)
+}
+
+// checkOverlaps makes sure no two entries of a table cover the same prefixes,
+// unless one is nested in the other with a longer prefix, as an 8 digit BIN
+// carved out of a 6 digit one
+func checkOverlaps(entries []BINEntry) error {
+	sorted := append([]BINEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		lowI, highI := sorted[i].Range.bounds()
+		lowJ, highJ := sorted[j].Range.bounds()
+		if lowI != lowJ {
+			return lowI < lowJ
+		}
+		return highI > highJ
+	})
+
+	// open holds the chain of ranges the current one may be nested in
+	var open []BINEntry
+	for _, cur := range sorted {
+		curLow, curHigh := cur.Range.bounds()
+
+		for len(open) > 0 {
+			if _, high := open[len(open)-1].Range.bounds(); high >= curLow {
+				break
+			}
+			open = open[:len(open)-1]
+		}
+
+		if len(open) > 0 {
+			parent := open[len(open)-1]
+			_, parentHigh := parent.Range.bounds()
+
+			if curHigh > parentHigh || cur.Range.Digits <= parent.Range.Digits {
+				first, second := parent, cur
+				if second.Line < first.Line {
+					first, second = second, first
+				}
+
+				return &BINTableError{second.Line, fmt.Sprintf("range overlaps with the range on line %d", first.Line)}
+			}
+		}
+
+		open = append(open, cur)
+	}
+
+	return nil
+}
+
+// tableBase is a scheme of a registry as it was before BIN tables were loaded
+type tableBase struct {
+	// scheme is the scheme of the registry's own layer, nil if it had none
+	scheme *Scheme
+
+	// created is set for the schemes BIN tables created, with their priority
+	created  bool
+	priority int
+}
+
+// LoadBINTable loads a BIN table into the registry, replacing the table
+// loaded before, so that a table can be refreshed by loading its new version.
+// Ranges of a brand that is already registered are added to its scheme;
+// other brands are registered as new schemes checked before the existing
+// ones, as BIN table ranges are usually narrower than the built-in ones.
+// Brands of the previous table missing from the new one get their scheme
+// back as it was before any table was loaded. The registry switches to the
+// new table at once. Registering or removing a scheme makes it the scheme
+// later tables are added to.
+func (r *Registry) LoadBINTable(entries []BINEntry) error {
+	if err := checkOverlaps(entries); err != nil {
+		return err
+	}
+
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	r.mu.RLock()
+	local := map[string]Scheme{}
+	for _, s := range r.schemes {
+		local[s.Company.Short] = s
+	}
+	previous := make(map[string]tableBase, len(r.tables))
+	for short, base := range r.tables {
+		previous[short] = base
+	}
+	r.mu.RUnlock()
+
+	priority := r.lowestPriority(func(s Scheme) bool { return previous[s.Company.Short].created }) - 1
+
+	var order []string
+	bases := map[string]tableBase{}
+	schemes := map[string]*Scheme{}
+
+	for _, entry := range entries {
+		s, ok := schemes[entry.Company.Short]
+		if !ok {
+			base, tracked := previous[entry.Company.Short]
+			if !tracked {
+				if l, found := local[entry.Company.Short]; found {
+					base.scheme = &l
+				} else if _, found := r.parent.Scheme(entry.Company.Short); !found {
+					base.created, base.priority = true, priority
+				}
+			}
+
+			var scheme Scheme
+			switch {
+			case base.scheme != nil:
+				scheme = base.scheme.clone()
+			case base.created:
+				scheme = Scheme{Company: entry.Company, CVVLength: 3, Priority: base.priority}
+			default:
+				parent, _ := r.parent.Scheme(entry.Company.Short)
+				scheme = parent.clone()
+			}
+
+			s = &scheme
+			bases[entry.Company.Short] = base
+			schemes[entry.Company.Short] = s
+			order = append(order, entry.Company.Short)
+		}
+
+		s.Ranges = append(s.Ranges, entry.Range)
+		for _, l := range entry.Lengths {
+			if !containsInt(s.Lengths, l) {
+				s.Lengths = append(s.Lengths, l)
+			}
+		}
+	}
+
+	for _, short := range order {
+		sort.Ints(schemes[short].Lengths)
+		if err := schemes[short].validate(); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := make([]Scheme, 0, len(r.schemes)+len(order))
+	for _, s := range r.schemes {
+		short := s.Company.Short
+		if _, loaded := schemes[short]; loaded {
+			continue
+		}
+
+		if base, tracked := r.tables[short]; tracked {
+			if base.scheme != nil {
+				next = append(next, *base.scheme)
+			}
+			continue
+		}
+
+		next = append(next, s)
+	}
+
+	for _, short := range order {
+		next = append(next, *schemes[short])
+		if r.hidden[short] {
+			r.setHidden(short, false)
+		}
+	}
+
+	sort.SliceStable(next, func(i, j int) bool {
+		return next[i].Priority < next[j].Priority
+	})
+
+	r.schemes, r.tables = next, bases
+	return nil
+}
+
+// lowestPriority returns the priority of the first scheme checked, skipping
+// the ones skip reports
+func (r *Registry) lowestPriority(skip func(Scheme) bool) int {
+	priority := 0
+	r.visit(func(s Scheme) bool {
+		if skip(s) {
+			return true
+		}
+
+		priority = s.Priority
+		return false
+	})
+
+	return priority
+}
+
+// LoadCSV parses a CSV BIN table, see ParseBINCSV, and loads it into the registry
+func (r *Registry) LoadCSV(reader io.Reader) error {
+	entries, err := ParseBINCSV(reader)
+	if err != nil {
+		return err
+	}
+
+	return r.LoadBINTable(entries)
+}
+
+// LoadJSON parses a JSON BIN table, see ParseBINJSON, and loads it into the registry
+func (r *Registry) LoadJSON(reader io.Reader) error {
+	entries, err := ParseBINJSON(reader)
+	if err != nil {
+		return err
+	}
+
+	return r.LoadBINTable(entries)
+}
+
+// LoadBINCSV loads a CSV BIN table into the DefaultRegistry
+func LoadBINCSV(reader io.Reader) error {
+	return DefaultRegistry.LoadCSV(reader)
+}
+
+// LoadBINJSON loads a JSON BIN table into the DefaultRegistry
+func LoadBINJSON(reader io.Reader) error {
+	return DefaultRegistry.LoadJSON(reader)
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package creditcard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBINTable(t *testing.T) {
+	Convey("Should load BIN tables from CSV", t, func() {
+		table := `start,end,short,long,lengths,issuer,country,type
+497010,497019,cb,Cartes Bancaires,16,Banque Example,FR,debit
+6060,6060,private,Private Label,16;18-19,,,
+`
+		entries, err := ParseBINCSV(strings.NewReader(table))
+
+		So(err, ShouldBeNil)
+		So(len(entries), ShouldEqual, 2)
+		So(entries[0].Line, ShouldEqual, 2)
+		So(entries[0].Company, ShouldResemble, Company{"cb", "Cartes Bancaires"})
+		So(entries[0].Range.Issuer, ShouldEqual, "Banque Example")
+		So(entries[0].Range.Country, ShouldEqual, "FR")
+		So(entries[1].Lengths, ShouldResemble, []int{16, 18, 19})
+
+		Convey("into a registry", func() {
+			registry := newBuiltinRegistry()
+			So(registry.LoadBINTable(entries), ShouldBeNil)
+
+			company, err := registry.Detect("4970151234567890")
+			So(err, ShouldBeNil)
+			So(company.Short, ShouldEqual, "cb")
+
+			company, _ = registry.Detect("4242424242424242")
+			So(company.Short, ShouldEqual, "visa")
+
+			s, ok := registry.Scheme("private")
+			So(ok, ShouldBeTrue)
+			So(s.Lengths, ShouldResemble, []int{16, 18, 19})
+		})
+
+		Convey("adding ranges to known brands", func() {
+			registry := newBuiltinRegistry()
+			err := registry.LoadCSV(strings.NewReader("start,end,short\n990000,990099,elo\n"))
+			So(err, ShouldBeNil)
+
+			company, _ := registry.Detect("9900501234567890")
+			So(company.Short, ShouldEqual, "elo")
+
+			company, _ = registry.Detect("6362970000457013")
+			So(company.Short, ShouldEqual, "elo")
+		})
+	})
+
+	Convey("Should replace the BIN table loaded before", t, func() {
+		january := "start,end,short,long,lengths\n990000,990099,elo,,\n497010,497019,cb,Cartes Bancaires,16\n"
+		february := "start,end,short,long,lengths\n990100,990199,elo,,\n"
+
+		registry := newBuiltinRegistry()
+		builtin, _ := registry.Scheme("elo")
+
+		So(registry.LoadCSV(strings.NewReader(january)), ShouldBeNil)
+		So(registry.LoadCSV(strings.NewReader(january)), ShouldBeNil)
+
+		elo, _ := registry.Scheme("elo")
+		So(len(elo.Ranges), ShouldEqual, len(builtin.Ranges)+1)
+		cb, ok := registry.Scheme("cb")
+		So(ok, ShouldBeTrue)
+		priority := cb.Priority
+
+		So(registry.LoadCSV(strings.NewReader(february)), ShouldBeNil)
+
+		elo, _ = registry.Scheme("elo")
+		So(len(elo.Ranges), ShouldEqual, len(builtin.Ranges)+1)
+		So(elo.Ranges[len(elo.Ranges)-1], ShouldResemble, PrefixRange("990100", "990199"))
+
+		_, err := registry.Detect("9900501234567890")
+		So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+		company, _ := registry.Detect("9901501234567890")
+		So(company.Short, ShouldEqual, "elo")
+
+		_, ok = registry.Scheme("cb")
+		So(ok, ShouldBeFalse)
+		company, _ = registry.Detect("4970151234567890")
+		So(company.Short, ShouldEqual, "visa")
+
+		Convey("keeping the priority of the brands it creates", func() {
+			So(registry.LoadCSV(strings.NewReader(january)), ShouldBeNil)
+			cb, _ := registry.Scheme("cb")
+			So(cb.Priority, ShouldEqual, priority)
+		})
+
+		Convey("restoring overridden parent schemes in layers", func() {
+			layer := newBuiltinRegistry().Layer()
+			So(layer.LoadCSV(strings.NewReader(january)), ShouldBeNil)
+			So(layer.LoadCSV(strings.NewReader("start,end,short\n6060,6060,private\n")), ShouldBeNil)
+
+			elo, _ := layer.Scheme("elo")
+			So(elo.Ranges, ShouldResemble, builtin.Ranges)
+			So(len(layer.Schemes()), ShouldEqual, len(BuiltinSchemes())+1)
+		})
+
+		Convey("without undoing schemes registered since", func() {
+			custom := builtin.clone()
+			custom.Ranges = append(custom.Ranges, Prefix("7777"))
+			So(registry.Register(custom), ShouldBeNil)
+
+			So(registry.LoadCSV(strings.NewReader(january)), ShouldBeNil)
+			So(registry.LoadCSV(strings.NewReader("start,end,short\n6060,6060,private\n")), ShouldBeNil)
+
+			elo, _ := registry.Scheme("elo")
+			So(elo.Ranges, ShouldResemble, custom.Ranges)
+		})
+	})
+
+	Convey("Should load BIN tables from JSON", t, func() {
+		table := `[
+  {"start": "497010", "end": "497019", "short": "cb", "long": "Cartes Bancaires", "lengths": [16]},
+  {"start": "6060", "end": "6060", "short": "private"}
+]`
+		entries, err := ParseBINJSON(strings.NewReader(table))
+
+		So(err, ShouldBeNil)
+		So(len(entries), ShouldEqual, 2)
+		So(entries[1].Line, ShouldEqual, 3)
+		So(entries[1].Company, ShouldResemble, Company{"private", "private"})
+
+		registry := NewRegistry()
+		So(registry.LoadJSON(strings.NewReader(table)), ShouldBeNil)
+		So(len(registry.Schemes()), ShouldEqual, 2)
+	})
+
+	Convey("Should refuse invalid BIN tables with the offending line", t, func() {
+		lineOf := func(err error) int {
+			var tErr *BINTableError
+			if errors.As(err, &tErr) {
+				return tErr.Line
+			}
+			return 0
+		}
+
+		Convey("Inverted ranges", func() {
+			_, err := ParseBINCSV(strings.NewReader("start,end,short\n650031,650051,elo\n650035,650033,elo\n"))
+			So(lineOf(err), ShouldEqual, 3)
+			So(err.Error(), ShouldContainSubstring, "inverted")
+		})
+
+		Convey("Overlapping ranges", func() {
+			_, err := ParseBINCSV(strings.NewReader("start,end,short\n650031,650051,elo\n4,4,visa\n650050,650060,discover\n"))
+			So(lineOf(err), ShouldEqual, 4)
+			So(err.Error(), ShouldContainSubstring, "line 2")
+		})
+
+		Convey("Identical ranges", func() {
+			_, err := ParseBINCSV(strings.NewReader("start,end,short\n6500,6500,elo\n6500,6500,discover\n"))
+			So(lineOf(err), ShouldEqual, 3)
+		})
+
+		Convey("Mismatched lengths", func() {
+			_, err := ParseBINJSON(strings.NewReader(`[{"start": "4", "end": "49", "short": "visa"}]`))
+			So(lineOf(err), ShouldEqual, 1)
+		})
+
+		Convey("Non numeric prefixes", func() {
+			_, err := ParseBINCSV(strings.NewReader("start,end,short\n4a,4b,visa\n"))
+			So(lineOf(err), ShouldEqual, 2)
+		})
+
+		Convey("Missing columns", func() {
+			_, err := ParseBINCSV(strings.NewReader("start,short\n4,visa\n"))
+			So(lineOf(err), ShouldEqual, 1)
+		})
+
+		Convey("Unknown JSON keys", func() {
+			_, err := ParseBINJSON(strings.NewReader("[\n{\"start\": \"4\", \"end\": \"4\", \"short\": \"visa\"},\n{\"begin\": \"5\"}\n]"))
+			So(lineOf(err), ShouldEqual, 3)
+		})
+	})
+
+	Convey("Should accept 8 digit BINs nested in 6 digit ones", t, func() {
+		table := `start,end,short,issuer
+411111,411111,visa,Shared BIN Bank
+41111100,41111124,visa,First Bank
+41111150,41111174,visa,Second Bank
+`
+		registry := newBuiltinRegistry()
+		So(registry.LoadCSV(strings.NewReader(table)), ShouldBeNil)
+
+		info, err := registry.Resolve("4111110012345678")
+		So(err, ShouldBeNil)
+		So(info.Range.Issuer, ShouldEqual, "First Bank")
+
+		info, _ = registry.Resolve("4111115512345678")
+		So(info.Range.Issuer, ShouldEqual, "Second Bank")
+
+		info, _ = registry.Resolve("4111113012345678")
+		So(info.Range.Issuer, ShouldEqual, "Shared BIN Bank")
+		So(info.Company.Short, ShouldEqual, "visa")
+	})
+
+	Convey("Registering a scheme with an inverted range should fail", t, func() {
+		err := NewRegistry().Register(Scheme{
+			Company: Company{"elo", "Elo"},
+			Ranges:  []IINRange{PrefixRange("650035", "650033")},
+		})
+
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,173 @@
+package creditcard
+
+import (
+	"sync"
+	"time"
+)
+
+// CardholderData is the part of a card PCI DSS allows to store once
+// protected: its number, expiration date and company. It holds no
+// sensitive authentication data, see AuthenticationData.
+type CardholderData struct {
+	PAN         *Sensitive
+	Month, Year string
+	Company     Company
+}
+
+// Card returns a card made of the cardholder data, sharing its PAN, with
+// CvvPresence CVVNotProvided, e.g. to validate a card on file
+func (d *CardholderData) Card() Card {
+	return Card{
+		SecureNumber: d.PAN,
+		Month:        d.Month,
+		Year:         d.Year,
+		Company:      d.Company,
+		CvvPresence:  CVVNotProvided,
+	}
+}
+
+// Authorize returns a card made of the cardholder data and of the CVV of the
+// authentication data, consuming it. The returned card's SecureCvv should be
+// wiped once the authorization is done.
+func (d *CardholderData) Authorize(a *AuthenticationData) (Card, error) {
+	cvv, presence, err := a.Consume()
+	if err != nil {
+		return Card{}, err
+	}
+
+	card := d.Card()
+	card.SecureCvv, card.CvvPresence = cvv, presence
+	return card, nil
+}
+
+// Wipe zeroizes the PAN
+func (d *CardholderData) Wipe() {
+	d.PAN.Wipe()
+}
+
+// AuthenticationData is the sensitive authentication data of a card, its
+// CVV, which PCI DSS forbids to keep after authorization. The CVV can only
+// be consumed once, before its time to live runs out; it is wiped either way.
+// It is safe for concurrent use.
+type AuthenticationData struct {
+	mu        sync.Mutex
+	cvv       *Sensitive
+	presence  CVVPresence
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+// AuthenticationOption configures AuthenticationData built by
+// NewAuthenticationData
+type AuthenticationOption func(*AuthenticationData)
+
+// WithAuthenticationClock measures the time to live of the CVV with the
+// given clock instead of time.Now
+func WithAuthenticationClock(now func() time.Time) AuthenticationOption {
+	return func(a *AuthenticationData) {
+		a.now = now
+	}
+}
+
+// NewAuthenticationData returns authentication data holding a copy of the
+// CVV, which can be consumed until the time to live runs out, forever when
+// ttl is zero
+func NewAuthenticationData(cvv string, presence CVVPresence, ttl time.Duration, options ...AuthenticationOption) *AuthenticationData {
+	a := &AuthenticationData{cvv: NewSensitiveString(cvv), presence: presence, now: time.Now}
+	for _, option := range options {
+		option(a)
+	}
+
+	if ttl > 0 {
+		a.expiresAt = a.now().Add(ttl)
+	}
+
+	return a
+}
+
+// ExpiresAt returns the time after which the CVV cannot be consumed, the zero
+// time when it has no time to live
+func (a *AuthenticationData) ExpiresAt() time.Time {
+	return a.expiresAt
+}
+
+// Consume returns the CVV and its presence indicator, and wipes the
+// authentication data: later calls fail with ErrWiped, as do calls made once
+// the time to live has run out. The caller owns the returned CVV and should
+// wipe it once done.
+func (a *AuthenticationData) Consume() (*Sensitive, CVVPresence, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cvv.Wiped() || (!a.expiresAt.IsZero() && a.now().After(a.expiresAt)) {
+		a.cvv.Wipe()
+		return nil, a.presence, newValidationError(CodeWiped, FieldCVV, ErrWiped)
+	}
+
+	b, err := a.cvv.Bytes()
+	a.cvv.Wipe()
+	if err != nil {
+		return nil, a.presence, err
+	}
+	defer zeroBytes(b)
+
+	return NewSensitive(b), a.presence, nil
+}
+
+// Expired reports whether the CVV was consumed, wiped or outlived its time to live
+func (a *AuthenticationData) Expired() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.cvv.Wiped() || (!a.expiresAt.IsZero() && a.now().After(a.expiresAt))
+}
+
+// Wipe zeroizes the CVV without consuming it
+func (a *AuthenticationData) Wipe() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cvv.Wipe()
+}
+
+// String never returns the CVV
+func (a *AuthenticationData) String() string {
+	return a.cvv.String()
+}
+
+// CardholderData returns a copy of the card's cardholder data, see Split.
+// The PAN of a wiped card is wiped.
+func (c *Card) CardholderData() *CardholderData {
+	d := &CardholderData{
+		PAN:     NewSensitiveString(c.number()),
+		Month:   c.Month,
+		Year:    c.Year,
+		Company: c.Company,
+	}
+
+	if c.Wiped() {
+		d.PAN.Wipe()
+	}
+
+	return d
+}
+
+// AuthenticationData returns a copy of the card's CVV which can be consumed
+// until the time to live runs out, see NewAuthenticationData and Split. The
+// CVV of a wiped card is wiped.
+func (c *Card) AuthenticationData(ttl time.Duration, options ...AuthenticationOption) *AuthenticationData {
+	a := NewAuthenticationData(c.cvv(), c.CvvPresence, ttl, options...)
+	if c.checkCVV() != nil {
+		a.Wipe()
+	}
+
+	return a
+}
+
+// Split copies the card into its cardholder data and its sensitive
+// authentication data, for callers migrating away from Card. The card should
+// be wiped afterwards. The options configure the authentication data, see
+// WithAuthenticationClock.
+func (c *Card) Split(ttl time.Duration, options ...AuthenticationOption) (*CardholderData, *AuthenticationData) {
+	return c.CardholderData(), c.AuthenticationData(ttl, options...)
+}
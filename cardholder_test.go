@@ -0,0 +1,125 @@
+package creditcard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCardholderData(t *testing.T) {
+	Convey("Should split a card into cardholder and authentication data", t, func() {
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		data, auth := card.Split(time.Minute)
+		card.Wipe()
+
+		So(data.PAN.EqualBytes([]byte("4556974850403706")), ShouldBeTrue)
+		So(data.Month, ShouldEqual, "02")
+		So(data.Year, ShouldEqual, "2099")
+		So(auth.Expired(), ShouldBeFalse)
+		So(fmt.Sprintf("%v", auth), ShouldEqual, "[REDACTED]")
+
+		Convey("The cardholder data validates without a CVV", func() {
+			onFile := data.Card()
+			So(onFile.Validate(), ShouldBeNil)
+			So(onFile.String(), ShouldEqual, "{Number:************3706 Cvv: Month:02 Year:2099 Company:}")
+		})
+
+		Convey("The CVV can only be consumed once", func() {
+			authorized, err := data.Authorize(auth)
+			So(err, ShouldBeNil)
+			So(authorized.Validate(), ShouldBeNil)
+			So(authorized.SecureCvv.EqualBytes([]byte("111")), ShouldBeTrue)
+			So(auth.Expired(), ShouldBeTrue)
+
+			_, err = data.Authorize(auth)
+			So(errors.Is(err, ErrWiped), ShouldBeTrue)
+			So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+
+			authorized.SecureCvv.Wipe()
+			So(data.PAN.Wiped(), ShouldBeFalse)
+		})
+
+		Convey("Wiping the cardholder data wipes the cards made of it", func() {
+			onFile := data.Card()
+			data.Wipe()
+			So(onFile.Wiped(), ShouldBeTrue)
+		})
+	})
+
+	Convey("Should not consume the CVV after its time to live", t, func() {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		clock := WithAuthenticationClock(func() time.Time { return now })
+		auth := NewAuthenticationData("111", CVVProvided, time.Minute, clock)
+		So(auth.ExpiresAt(), ShouldEqual, now.Add(time.Minute))
+
+		now = now.Add(time.Minute)
+		So(auth.Expired(), ShouldBeFalse)
+
+		now = now.Add(time.Second)
+		So(auth.Expired(), ShouldBeTrue)
+
+		_, _, err := auth.Consume()
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		So(auth.Expired(), ShouldBeTrue)
+
+		Convey("Split cards use the same clock", func() {
+			card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+			data, auth := card.Split(time.Minute, clock)
+
+			now = now.Add(time.Minute)
+			_, err := data.Authorize(auth)
+			So(err, ShouldBeNil)
+
+			_, auth = card.Split(time.Minute, clock)
+			now = now.Add(time.Minute + time.Second)
+			_, err = data.Authorize(auth)
+			So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		})
+	})
+
+	Convey("A CVV without time to live lasts until consumed", t, func() {
+		auth := NewAuthenticationData("1234", CVVProvided, 0)
+		So(auth.Expired(), ShouldBeFalse)
+		So(auth.ExpiresAt().IsZero(), ShouldBeTrue)
+
+		auth.Wipe()
+		_, _, err := auth.Consume()
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+	})
+
+	Convey("Only one concurrent consumer should get the CVV", t, func() {
+		auth := NewAuthenticationData("111", CVVProvided, time.Minute)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		consumed := 0
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := auth.Consume(); err == nil {
+					mu.Lock()
+					consumed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		So(consumed, ShouldEqual, 1)
+	})
+
+	Convey("Should keep a wiped card wiped", t, func() {
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		card.Wipe()
+
+		data, auth := card.Split(time.Minute)
+		So(data.PAN.Wiped(), ShouldBeTrue)
+		So(auth.Expired(), ShouldBeTrue)
+	})
+}
@@ -1,14 +1,20 @@
 package creditcard
 
-import (
-	"errors"
-	"strconv"
-)
+import "strconv"
 
 // Card holds generic information about the credit card
 type Card struct {
 	Number, Cvv, Month, Year string
 	Company                  Company
+
+	// CvvPresence tells whether Cvv was given, it is CVVProvided by default
+	CvvPresence CVVPresence
+
+	// SecureNumber and SecureCvv, when set, hold the number and CVV in place
+	// of Number and Cvv, see Secure
+	SecureNumber, SecureCvv *Sensitive
+
+	wiped bool
 }
 
 // Company holds a short and long names of who has issued the credit card
@@ -16,20 +22,67 @@ type Company struct {
 	Short, Long string
 }
 
-type digits [6]int
+// digits holds the leading digits of a card number: 8 digit BINs as well as
+// longer private label prefixes fit in it
+type digits [12]int
+
+// minDigits is how many leading characters of a number must be digits for
+// a company to be detected
+const minDigits = 6
+
+// newDigits parses the leading digits of a card number. Parsing stops at the
+// first non-digit character, which is an error within the first minDigits.
+func newDigits(number string) (digits, error) {
+	var err error
+	ccDigits := digits{}
+
+	for i := 0; i < len(ccDigits) && i < len(number); i++ {
+		ccDigits[i], err = parseDigits(number[:i+1])
+		if err != nil {
+			if i < minDigits {
+				return ccDigits, err
+			}
+			break
+		}
+	}
+
+	return ccDigits, nil
+}
 
 // At returns the digits from the start to the given length
 func (d *digits) At(i int) int {
 	return d[i-1]
 }
 
+// parseDigits converts a string made only of ASCII digits to an int
+func parseDigits(s string) (int, error) {
+	if s == "" {
+		return 0, strconv.ErrSyntax
+	}
+
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, strconv.ErrSyntax
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+
+	return n, nil
+}
+
 // LastFour returns the last four digits of the credit card's number
 func (c *Card) LastFour() (string, error) {
-	if len(c.Number) < 4 {
-		return "", errors.New("Credit card number is not long enough")
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return "", err
+	}
+
+	if len(number) < 4 {
+		return "", newValidationError(CodeNumberTooShort, FieldNumber, ErrNumberTooShort)
 	}
 
-	return c.Number[len(c.Number)-4 : len(c.Number)], nil
+	return number[len(number)-4:], nil
 }
 
 // LastFourDigits as an alias for LastFour
@@ -37,200 +90,166 @@ func (c *Card) LastFourDigits() (string, error) {
 	return c.LastFour()
 }
 
-// Wipe returns the credit card with false/nullified/generic information
+// Wipe clears the card's number, CVV and expiration date, zeroizes
+// SecureNumber and SecureCvv, and marks the card as wiped so that validation
+// rejects it with ErrWiped.
 func (c *Card) Wipe() {
-	c.Cvv, c.Number, c.Month, c.Year = "0000", "0000000000000000", "01", "1970"
+	c.SecureNumber.Wipe()
+	c.SecureCvv.Wipe()
+	c.Cvv, c.Number, c.Month, c.Year = "", "", "", ""
+	c.wiped = true
 }
 
 // Validate returns nil or an error describing why the credit card didn't validate
 // this method checks for expiration date, CCV/CVV and the credit card's numbers.
 // For allowing test cards to go through, simply pass true (bool) as the first argument
 func (c *Card) Validate(allowTestNumbers ...bool) error {
-	err := c.ValidateExpiration()
-	if err != nil {
-		return err
-	}
+	return c.ValidateWith(ValidateOptions{AllowTestNumbers: len(allowTestNumbers) > 0 && allowTestNumbers[0]})
+}
 
-	err = c.ValidateCVV()
-	if err != nil {
-		return err
-	}
+// ValidateWith validates the card like Validate, with the given options
+func (c *Card) ValidateWith(opts ValidateOptions) error {
+	return defaultValidator.withOptions(opts).Validate(*c)
+}
 
-	switch c.Number {
-	// test cards: https://stripe.com/docs/testing
-	case "4242424242424242",
-		"4012888888881881",
-		"4000056655665556",
-		"5555555555554444",
-		"5200828282828210",
-		"5105105105105100",
-		"378282246310005",
-		"371449635398431",
-		"6011111111111117",
-		"6011000990139424",
-		"30569309025904",
-		"38520000023237",
-		"3530111333300000",
-		"3566002020360505",
-		"4111111111111111",
-		"4916909992637469",
-		"4000111111111115",
-		"2223000048400011",
-		"6035227716427021":
-		if len(allowTestNumbers) > 0 && allowTestNumbers[0] {
-			return nil
-		}
+// validates the credit card's expiration date. Two digit years are resolved
+// with the sliding century window described in ParseExpiry.
+func (c *Card) ValidateExpiration() error {
+	return defaultValidator.ValidateExpiration(*c)
+}
 
-		return errors.New("Test numbers are not allowed")
-	}
+// ValidateCVV validates the card's CVV value against its company: American
+// Express uses a 4 digits CID, the others 3 digits. Numbers of an unknown
+// company accept either. When CvvPresence says no code was provided, Cvv must
+// be empty, and a code can only be missing from the card for companies which
+// do not always print one.
+func (c *Card) ValidateCVV() error {
+	return defaultValidator.ValidateCVV(*c)
+}
 
-	valid := c.ValidateNumber()
+// Method returns an error from MethodValidate() or returns the
+// credit card with it's company / issuer attached to it
+func (c *Card) Method() error {
+	company, err := c.MethodValidate()
 
-	if !valid {
-		return errors.New("Invalid credit card number")
+	if err != nil {
+		return err
 	}
 
+	c.Company = company
 	return nil
 }
 
-// validates the credit card's expiration date
-func (c *Card) ValidateExpiration() error {
-	var year, month int
-	var err error
-	timeNow := timeNowCaller()
+// MethodValidate adds/checks/verifies the credit card's company / issuer
+// against the schemes of the DefaultRegistry
+func (c *Card) MethodValidate() (Company, error) {
+	return defaultValidator.MethodValidate(*c)
+}
 
-	if len(c.Year) < 3 {
-		year, err = strconv.Atoi(strconv.Itoa(timeNow.UTC().Year())[:2] + c.Year)
-		if err != nil {
-			return errors.New("Invalid year")
-		}
-	} else {
-		year, err = strconv.Atoi(c.Year)
-		if err != nil {
-			return errors.New("Invalid year")
-		}
+// Companies returns every company of the DefaultRegistry the card's number
+// belongs to, flagging the one MethodValidate returns as primary
+func (c *Card) Companies() ([]CompanyMatch, error) {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return nil, err
 	}
 
-	month, err = strconv.Atoi(c.Month)
+	return DefaultRegistry.DetectAll(number)
+}
+
+// SelectCompany attaches the given company to a co-badged card, as chosen by
+// the cardholder. It fails when the card's number does not belong to it.
+func (c *Card) SelectCompany(short string) error {
+	matches, err := c.Companies()
 	if err != nil {
-		return errors.New("Invalid month")
+		return err
 	}
 
-	if month < 1 || 12 < month {
-		return errors.New("Invalid month")
+	for _, m := range matches {
+		if m.Company.Short == short {
+			c.Company = m.Company
+			return nil
+		}
 	}
 
-	if year < timeNowCaller().UTC().Year() {
-		return errors.New("Credit card has expired")
-	}
+	return newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+}
 
-	if year == timeNowCaller().UTC().Year() && month < int(timeNowCaller().UTC().Month()) {
-		return errors.New("Credit card has expired")
+// Issuer returns the most specific IIN range of the DefaultRegistry the card's
+// number belongs to, along with its company
+func (c *Card) Issuer() (IssuerInfo, error) {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return IssuerInfo{}, err
 	}
 
-	return nil
+	return DefaultRegistry.Resolve(number)
 }
 
-// validates the length of the card's CVV value
-func (c *Card) ValidateCVV() error {
-	if len(c.Cvv) < 3 || len(c.Cvv) > 4 {
-		return errors.New("Invalid CVV")
+// Luhn algorithm
+// http://en.wikipedia.org/wiki/Luhn_algorithm
+
+// ValidateNumber will check the credit card's number against the Luhn algorithm
+func (c *Card) ValidateNumber() bool {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return false
 	}
 
-	return nil
+	return hasValidLength(number) && luhn(number)
 }
 
-// Method returns an error from MethodValidate() or returns the
-// credit card with it's company / issuer attached to it
-func (c *Card) Method() error {
-	company, err := c.MethodValidate()
+// normalizedNumber returns the canonical form of the card's number, or the
+// number as is when it cannot be normalized
+func (c *Card) normalizedNumber() string {
+	if number, err := c.NormalizedNumber(); err == nil {
+		return number
+	}
 
-	if err != nil {
-		return err
+	return c.number()
+}
+
+// hasValidChecksum checks the number against the checksum rule of its scheme,
+// falling back to the Luhn algorithm for unknown numbers
+func (r *Registry) hasValidChecksum(number string) bool {
+	if s, err := r.detect(number); err == nil && s.Checksum == ChecksumNone {
+		return true
 	}
 
-	c.Company = company
-	return nil
+	return luhn(number)
 }
 
-// MethodValidate adds/checks/verifies the credit card's company / issuer
-func (c *Card) MethodValidate() (Company, error) {
-	var err error
-	ccLen := len(c.Number)
-	ccDigits := digits{}
+// ValidateLength checks the length of the card's number against the generic
+// 13 to 19 digits window, then against the lengths allowed by its company.
+// Numbers of an unknown company are only checked against the generic window.
+func (c *Card) ValidateLength() error {
+	return defaultValidator.ValidateLength(*c)
+}
 
-	for i := 0; i < 6; i++ {
-		if i < ccLen {
-			ccDigits[i], err = strconv.Atoi(c.Number[:i+1])
-			if err != nil {
-				return Company{"", ""}, errors.New("Unknown credit card method")
-			}
-		}
+func (r *Registry) validateLength(number string) error {
+	if !hasValidLength(number) {
+		return newValidationError(CodeInvalidLength, FieldNumber, ErrInvalidLength)
 	}
 
-	switch {
-	case isAmex(ccDigits):
-		return Company{"amex", "American Express"}, nil
-	case isBankCard(ccDigits):
-		return Company{"bankcard", "Bankcard"}, nil
-	case isCabal(ccDigits):
-		return Company{"cabal", "Cabal"}, nil
-	case isUnionPay(ccDigits):
-		return Company{"china unionpay", "China UnionPay"}, nil
-	case isDinersClubCarteBlanche(ccDigits, ccLen):
-		return Company{"diners club carte blanche", "Diners Club Carte Blanche"}, nil
-	case isDinersClubEnroute(ccDigits):
-		return Company{"diners club enroute", "Diners Club enRoute"}, nil
-	case isDinersClubInternational(ccDigits, ccLen):
-		return Company{"diners club international", "Diners Club International"}, nil
-	case isDiscover(ccDigits):
-		return Company{"discover", "Discover"}, nil
-	// Elo must be checked before interpayment
-	case isElo(ccDigits):
-		return Company{"elo", "Elo"}, nil
-	case isHipercard(ccDigits):
-		return Company{"hipercard", "Hipercard"}, nil
-	case isInterpayment(ccDigits, ccLen):
-		return Company{"interpayment", "InterPayment"}, nil
-	case isInstapayment(ccDigits, ccLen):
-		return Company{"instapayment", "InstaPayment"}, nil
-	case isJCB(ccDigits):
-		return Company{"jcb", "JCB"}, nil
-	case isNaranja(ccDigits):
-		return Company{"naranja", "Naranja"}, nil
-	case isMaestro(c, ccDigits):
-		return Company{"maestro", "Maestro"}, nil
-	case isDankort(ccDigits):
-		return Company{"dankort", "Dankort"}, nil
-	case isMasterCard(ccDigits):
-		return Company{"mastercard", "MasterCard"}, nil
-	case isVisaElectron(ccDigits):
-		return Company{"visa electron", "Visa Electron"}, nil
-	case isVisa(ccDigits):
-		return Company{"visa", "Visa"}, nil
-	case isAura(ccDigits):
-		return Company{"aura", "Aura"}, nil
-	default:
-		return Company{"", ""}, errors.New("Unknown credit card method")
+	if s, err := r.detect(number); err == nil && !s.AllowsLength(len(number)) {
+		return newValidationError(CodeWrongLengthForCompany, FieldNumber, ErrWrongLengthForCompany)
 	}
+
+	return nil
 }
 
-// Luhn algorithm
-// http://en.wikipedia.org/wiki/Luhn_algorithm
+// hasValidLength checks the number against the generic 13 to 19 digits window
+func hasValidLength(number string) bool {
+	return len(number) >= 13 && len(number) <= 19
+}
 
-// ValidateNumber will check the credit card's number against the Luhn algorithm
-func (c *Card) ValidateNumber() bool {
+// luhn expects a normalized number, made only of ASCII digits
+func luhn(number string) bool {
 	var sum int
 	var alternate bool
 
-	numberLen := len(c.Number)
-
-	if numberLen < 13 || numberLen > 19 {
-		return false
-	}
-
-	for i := numberLen - 1; i > -1; i-- {
-		mod, _ := strconv.Atoi(string(c.Number[i]))
+	for i := len(number) - 1; i > -1; i-- {
+		mod := int(number[i] - '0')
 		if alternate {
 			mod *= 2
 			if mod > 9 {
@@ -246,15 +265,6 @@ func (c *Card) ValidateNumber() bool {
 	return sum%10 == 0
 }
 
-func matchesValue(number int, numbers []int) bool {
-	for _, v := range numbers {
-		if v == number {
-			return true
-		}
-	}
-	return false
-}
-
 func isInBetween(n, min, max int) bool {
 	return n >= min && n <= max
 }
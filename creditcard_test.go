@@ -1,6 +1,8 @@
 package creditcard
 
 import (
+	"encoding/json"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
@@ -43,10 +45,16 @@ func TestWipe(t *testing.T) {
 		card := Card{Number: "4012888888881881", Cvv: "111", Month: "02", Year: "2015"}
 		card.Wipe()
 
-		So(card.Number, ShouldEqual, "0000000000000000")
-		So(card.Cvv, ShouldEqual, "0000")
-		So(card.Month, ShouldEqual, "01")
-		So(card.Year, ShouldEqual, "1970")
+		So(card.Number, ShouldEqual, "")
+		So(card.Cvv, ShouldEqual, "")
+		So(card.Month, ShouldEqual, "")
+		So(card.Year, ShouldEqual, "")
+		So(card.Wiped(), ShouldBeTrue)
+		So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeWiped)
+
+		data, err := json.Marshal(card.Unredacted())
+		So(err, ShouldBeNil)
+		So(string(data), ShouldNotContainSubstring, "0000")
 	})
 }
 
@@ -122,13 +130,18 @@ func TestValidation(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 
-		Convey("Should work with four characters", func() {
-			card := Card{Number: "4012888888881881", Cvv: "1111", Month: month, Year: year}
+		Convey("Should work with four characters for American Express", func() {
+			card := Card{Number: "378282246310005", Cvv: "1111", Month: month, Year: year}
 			err := card.Validate(true)
 
 			So(err, ShouldBeNil)
 		})
 
+		Convey("Should work with three or four characters for unknown companies", func() {
+			So((&Card{Number: "9000000000000000008", Cvv: "111"}).ValidateCVV(), ShouldBeNil)
+			So((&Card{Number: "9000000000000000008", Cvv: "1111"}).ValidateCVV(), ShouldBeNil)
+		})
+
 		Convey("Should give us an error if CVV is invalid", func() {
 			Convey("Too many numbers", func() {
 				card := Card{Number: "4012888888881881", Cvv: "11111", Month: month, Year: year}
@@ -143,6 +156,60 @@ func TestValidation(t *testing.T) {
 
 				So(err, ShouldNotBeNil)
 			})
+
+			Convey("Four numbers for Visa", func() {
+				card := Card{Number: "4012888888881881", Cvv: "1111", Month: month, Year: year}
+				err := card.Validate(true)
+
+				So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCVV)
+			})
+
+			Convey("Three numbers for American Express", func() {
+				card := Card{Number: "378282246310005", Cvv: "111", Month: month, Year: year}
+				err := card.Validate(true)
+
+				So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCVV)
+			})
+
+			Convey("Letters", func() {
+				card := Card{Number: "4012888888881881", Cvv: "1a1", Month: month, Year: year}
+				err := card.Validate(true)
+
+				So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+				So(errors.Is(err, ErrInvalidCharacter), ShouldBeTrue)
+			})
+		})
+
+		Convey("Should honour the presence indicator", func() {
+			Convey("Not on card for Maestro", func() {
+				card := Card{Number: "6759649826438453", CvvPresence: CVVNotOnCard}
+				So(card.ValidateCVV(), ShouldBeNil)
+			})
+
+			Convey("Not on card for Visa", func() {
+				card := Card{Number: "4012888888881881", CvvPresence: CVVNotOnCard}
+				err := card.ValidateCVV()
+
+				So(ErrorCodeOf(err), ShouldEqual, CodeCVVRequired)
+				So(errors.Is(err, ErrCVVRequired), ShouldBeTrue)
+			})
+
+			Convey("Not provided or illegible", func() {
+				for _, presence := range []CVVPresence{CVVNotProvided, CVVIllegible} {
+					card := Card{Number: "4012888888881881", CvvPresence: presence}
+					So(card.ValidateCVV(), ShouldBeNil)
+
+					card.Cvv = "111"
+					So(ErrorCodeOf(card.ValidateCVV()), ShouldEqual, CodeInvalidCVV)
+				}
+			})
+
+			Convey("With ISO 8583 indicators", func() {
+				So(CVVProvided.Indicator(), ShouldEqual, "1")
+				So(CVVNotProvided.Indicator(), ShouldEqual, "0")
+				So(CVVIllegible.Indicator(), ShouldEqual, "2")
+				So(CVVNotOnCard.Indicator(), ShouldEqual, "9")
+			})
 		})
 	})
 
@@ -162,7 +229,7 @@ func TestValidation(t *testing.T) {
 		})
 
 		Convey("Should not give us an error if the number is greater than or equal to 13 characters", func() {
-			card := Card{Number: "4012888888881881", Cvv: "1111", Month: month, Year: year}
+			card := Card{Number: "4012888888881881", Cvv: "111", Month: month, Year: year}
 			err := card.Validate(true)
 
 			So(err, ShouldBeNil)
@@ -175,7 +242,7 @@ func TestValidation(t *testing.T) {
 		Convey("should pass through", func() {
 			for _, num := range numbers {
 				Convey(num, func() {
-					card := Card{Number: num, Cvv: "1111", Month: month, Year: year}
+					card := Card{Number: num, Cvv: cvvFor(num), Month: month, Year: year}
 					err := card.Validate(true)
 
 					So(err, ShouldBeNil)
@@ -186,7 +253,7 @@ func TestValidation(t *testing.T) {
 		Convey("should not pass through", func() {
 			for _, num := range numbers {
 				Convey(num, func() {
-					card := Card{Number: num, Cvv: "1111", Month: month, Year: year}
+					card := Card{Number: num, Cvv: cvvFor(num), Month: month, Year: year}
 					err := card.Validate()
 
 					So(err, ShouldNotBeNil)
@@ -198,14 +265,14 @@ func TestValidation(t *testing.T) {
 	Convey("Should be able to validate a number with the Luhn algorithm", t, func() {
 		Convey("With a valid card", func() {
 			Convey("Test Card", func() {
-				card := Card{Number: "4242424242424242", Cvv: "1111", Month: month, Year: year}
+				card := Card{Number: "4242424242424242", Cvv: "111", Month: month, Year: year}
 				err := card.Validate(true)
 
 				So(err, ShouldBeNil)
 			})
 
 			Convey("Real Card", func() {
-				card := Card{Number: "4556974850403706", Cvv: "1111", Month: month, Year: year}
+				card := Card{Number: "4556974850403706", Cvv: "111", Month: month, Year: year}
 				err := card.Validate()
 
 				So(err, ShouldBeNil)
@@ -219,6 +286,20 @@ func TestValidation(t *testing.T) {
 			So(err, ShouldNotBeNil)
 		})
 
+		Convey("With a number of the wrong length for its company", func() {
+			card := Card{Number: "3400000000000000009", Cvv: "1111", Month: month, Year: year}
+			So(card.ValidateNumber(), ShouldBeTrue)
+
+			err := card.Validate()
+			So(ErrorCodeOf(err), ShouldEqual, CodeWrongLengthForCompany)
+			So(errors.Is(err, ErrWrongLengthForCompany), ShouldBeTrue)
+		})
+
+		Convey("With a number of an unknown company", func() {
+			card := Card{Number: "9000000000000000008", Cvv: "1111", Month: month, Year: year}
+			So(card.ValidateLength(), ShouldBeNil)
+		})
+
 		Convey("Not enough numbers", func() {
 			card := Card{Number: "424832", Cvv: "1111", Month: month, Year: year}
 			err := card.ValidateNumber()
@@ -228,6 +309,15 @@ func TestValidation(t *testing.T) {
 	})
 }
 
+// cvvFor returns a security code of the right length for the number's company
+func cvvFor(number string) string {
+	if number[:2] == "34" || number[:2] == "37" {
+		return "1111"
+	}
+
+	return "111"
+}
+
 func TestMethod(t *testing.T) {
 	month := strconv.Itoa(int(time.Now().UTC().Month()))
 	year := strconv.Itoa(time.Now().UTC().Year())
@@ -470,14 +560,74 @@ func TestMethod(t *testing.T) {
 
 func TestCard_ValidateExpiration(t *testing.T) {
 	Convey("should get an error if card year is current year and card month is before current month", t, func() {
-		defer resetMocks()
-		timeNowCaller = func() time.Time {
+		v := NewValidator(WithClock(func() time.Time {
 			return time.Date(2001, 3, 1, 1, 1, 1, 1, time.UTC)
-		}
+		}))
 		card := Card{Number: "4012888888881881", Cvv: "111", Month: "02", Year: "2001"}
 
-		err := card.ValidateExpiration()
+		err := v.ValidateExpiration(card)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Credit card has expired")
 	})
 }
+
+func TestValidationErrors(t *testing.T) {
+	Convey("Validation errors should carry a code and a field", t, func() {
+		Convey("For an expired card", func() {
+			card := Card{Number: "4012888888881881", Cvv: "111", Month: "02", Year: "2001"}
+			err := card.Validate(true)
+
+			var vErr *ValidationError
+			So(errors.As(err, &vErr), ShouldBeTrue)
+			So(vErr.Code, ShouldEqual, CodeExpired)
+			So(vErr.Field, ShouldEqual, FieldYear)
+			So(errors.Is(err, ErrExpired), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "Credit card has expired")
+		})
+
+		Convey("For an invalid month", func() {
+			card := Card{Number: "4012888888881881", Cvv: "111", Month: "13", Year: "2099"}
+			err := card.ValidateExpiration()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidMonth)
+			So(errors.Is(err, ErrInvalidMonth), ShouldBeTrue)
+		})
+
+		Convey("For an invalid CVV", func() {
+			card := Card{Number: "4012888888881881", Cvv: "11"}
+			err := card.ValidateCVV()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCVV)
+			So(errors.Is(err, ErrInvalidCVV), ShouldBeTrue)
+		})
+
+		Convey("For a test number", func() {
+			card := Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+			err := card.Validate()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeTestNumber)
+			So(errors.Is(err, ErrTestNumber), ShouldBeTrue)
+		})
+
+		Convey("For an unknown method", func() {
+			card := Card{Number: "1112424242"}
+			_, err := card.MethodValidate()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+			So(errors.Is(err, ErrUnknownMethod), ShouldBeTrue)
+		})
+
+		Convey("For a number that is too short", func() {
+			card := Card{Number: "123"}
+			_, err := card.LastFour()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeNumberTooShort)
+			So(errors.Is(err, ErrNumberTooShort), ShouldBeTrue)
+		})
+
+		Convey("ErrorCodeOf should be empty for foreign errors", func() {
+			So(ErrorCodeOf(errors.New("boom")), ShouldEqual, ErrorCode(""))
+			So(ErrorCodeOf(nil), ShouldEqual, ErrorCode(""))
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package creditcard
+
+// CVVPresence tells whether the card's security code (CVV, CVC or CID) was
+// given, mirroring the presence indicator of ISO 8583 authorization messages
+type CVVPresence int
+
+// Security code presence indicators. The zero value means the code was provided.
+const (
+	CVVProvided CVVPresence = iota
+	CVVNotProvided
+	CVVIllegible
+	CVVNotOnCard
+)
+
+// Indicator returns the ISO 8583 presence indicator digit
+func (p CVVPresence) Indicator() string {
+	switch p {
+	case CVVProvided:
+		return "1"
+	case CVVNotProvided:
+		return "0"
+	case CVVIllegible:
+		return "2"
+	case CVVNotOnCard:
+		return "9"
+	default:
+		return ""
+	}
+}
+
+func (p CVVPresence) String() string {
+	switch p {
+	case CVVProvided:
+		return "provided"
+	case CVVNotProvided:
+		return "not provided"
+	case CVVIllegible:
+		return "illegible"
+	case CVVNotOnCard:
+		return "not on card"
+	default:
+		return "unknown"
+	}
+}
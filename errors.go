@@ -0,0 +1,99 @@
+package creditcard
+
+import "errors"
+
+// ErrorCode is a stable, machine-readable identifier for a validation failure
+type ErrorCode string
+
+// Error codes returned by ValidationError.Code
+const (
+	CodeNumberTooShort        ErrorCode = "number_too_short"
+	CodeInvalidNumber         ErrorCode = "invalid_number"
+	CodeInvalidLength         ErrorCode = "invalid_length"
+	CodeInvalidCharacter      ErrorCode = "invalid_character"
+	CodeWrongLengthForCompany ErrorCode = "wrong_length_for_company"
+	CodeUnknownMethod         ErrorCode = "unknown_method"
+	CodeTestNumber            ErrorCode = "test_number"
+	CodeInvalidCVV            ErrorCode = "invalid_cvv"
+	CodeCVVRequired           ErrorCode = "cvv_required"
+	CodeInvalidMonth          ErrorCode = "invalid_month"
+	CodeInvalidYear           ErrorCode = "invalid_year"
+	CodeExpired               ErrorCode = "expired"
+	CodeInvalidExpiry         ErrorCode = "invalid_expiry"
+	CodeBrandNotAccepted      ErrorCode = "brand_not_accepted"
+	CodeExpiryOutOfRange      ErrorCode = "expiry_out_of_range"
+	CodeWiped                 ErrorCode = "wiped"
+	CodeMaskPolicyViolation   ErrorCode = "mask_policy_violation"
+)
+
+// Field names the part of the card a validation failure refers to
+type Field string
+
+// Card fields reported by ValidationError.Field
+const (
+	FieldNumber Field = "number"
+	FieldCVV    Field = "cvv"
+	FieldMonth  Field = "month"
+	FieldYear   Field = "year"
+	FieldExpiry Field = "expiry"
+)
+
+// Sentinel errors, usable with errors.Is against any error returned by this package
+var (
+	ErrNumberTooShort        = errors.New("Credit card number is not long enough")
+	ErrInvalidNumber         = errors.New("Invalid credit card number")
+	ErrInvalidLength         = errors.New("Invalid credit card number length")
+	ErrInvalidCharacter      = errors.New("Invalid character")
+	ErrWrongLengthForCompany = errors.New("Invalid credit card number length for its company")
+	ErrUnknownMethod         = errors.New("Unknown credit card method")
+	ErrTestNumber            = errors.New("Test numbers are not allowed")
+	ErrInvalidCVV            = errors.New("Invalid CVV")
+	ErrCVVRequired           = errors.New("CVV is required for this credit card")
+	ErrInvalidMonth          = errors.New("Invalid month")
+	ErrInvalidYear           = errors.New("Invalid year")
+	ErrExpired               = errors.New("Credit card has expired")
+	ErrInvalidExpiry         = errors.New("Invalid expiration date")
+	ErrBrandNotAccepted      = errors.New("Credit card company is not accepted")
+	ErrExpiryOutOfRange      = errors.New("Credit card expiration date is out of the accepted range")
+	ErrWiped                 = errors.New("Credit card data has been wiped")
+	ErrMaskPolicyViolation   = errors.New("Masking policy reveals too many digits")
+)
+
+// ValidationError describes why a card failed validation. Callers should branch
+// on Code (or use errors.Is with the sentinel errors) rather than on the message.
+// Number is set on errors about the card's number when the validation options
+// chose a MaskPolicy, holding the number masked with it.
+type ValidationError struct {
+	Code   ErrorCode
+	Field  Field
+	Err    error
+	Number string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Number != "" {
+		return e.Err.Error() + " (" + e.Number + ")"
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause so errors.Is and errors.As can see through it
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(code ErrorCode, field Field, err error) *ValidationError {
+	return &ValidationError{Code: code, Field: field, Err: err}
+}
+
+// ErrorCodeOf returns the code of the first ValidationError in err's chain,
+// or an empty code if there is none
+func ErrorCodeOf(err error) ErrorCode {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		return vErr.Code
+	}
+
+	return ""
+}
@@ -0,0 +1,311 @@
+package creditcard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// yearWindowPast is how many years before the current one a two digit year
+// can refer to, see expandYear
+const yearWindowPast = 20
+
+// expandYear resolves a two digit year with a sliding century window: it is
+// the year ending with those digits from 20 years before to 79 years after
+// the current one. In 2026, "06" is 2006, "45" is 2045 and "99" is 2099;
+// in 2090, "05" is 2105.
+func expandYear(yy int, now time.Time) int {
+	start := now.UTC().Year() - yearWindowPast
+	year := start - start%100 + yy
+	if year < start {
+		year += 100
+	}
+
+	return year
+}
+
+var monthNames = []string{
+	"january", "february", "march", "april", "may", "june",
+	"july", "august", "september", "october", "november", "december",
+}
+
+// parseMonthName returns the month of an English month name, full or
+// abbreviated to at least three letters, e.g. "Dec" or "sept"
+func parseMonthName(s string) (int, bool) {
+	s = strings.ToLower(s)
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	for i, name := range monthNames {
+		if strings.HasPrefix(name, s) {
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// expiryTokens splits an expiration date into its runs of digits and of
+// letters. Spaces, slashes, dashes and dots separate them; Unicode decimal
+// digits are converted to ASCII.
+func expiryTokens(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	curDigits := false
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	position := 0
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			if !curDigits {
+				flush()
+			}
+			curDigits = true
+			cur.WriteByte(byte('0' + digitValue(r)))
+		case unicode.IsLetter(r):
+			if curDigits {
+				flush()
+			}
+			curDigits = false
+			cur.WriteRune(r)
+		case r == '/' || isSeparator(r):
+			flush()
+		default:
+			return nil, newValidationError(CodeInvalidCharacter, FieldExpiry, &CharacterError{Position: position, Char: r})
+		}
+		position++
+	}
+	flush()
+
+	return tokens, nil
+}
+
+func isDigitToken(s string) bool {
+	_, err := parseDigits(s)
+	return err == nil
+}
+
+// ParseExpiry parses an expiration date as typed by a cardholder or found in
+// imported files, returning its month and four digit year. The accepted
+// formats are:
+//
+//	MM/YY, MM/YYYY     "12/27", "12 / 2027", "12-27", "1/2027"
+//	MMYY, MMYYYY       "1227", "122027"
+//	YYYY-MM, YYYYMM    "2027-12", "2027/12", "202712"
+//	Month YY(YY)       "Dec 2027", "december 27", "Dec/27"
+//
+// Two digit years are resolved with a sliding century window: they refer to
+// the year ending with those digits from 20 years before to 79 years after
+// the current one. ISO 8583 YYMM dates, which are ambiguous with MMYY, are
+// parsed by ParseExpiryYYMM.
+func ParseExpiry(s string) (month, year int, err error) {
+	return defaultValidator.ParseExpiry(s)
+}
+
+// ParseExpiry parses an expiration date like the ParseExpiry function,
+// resolving two digit years against the validator's clock
+func (v *Validator) ParseExpiry(s string) (month, year int, err error) {
+	tokens, err := expiryTokens(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var m, y string
+	switch {
+	case len(tokens) == 1 && isDigitToken(tokens[0]) && len(tokens[0]) == 4:
+		m, y = tokens[0][:2], tokens[0][2:]
+	case len(tokens) == 1 && isDigitToken(tokens[0]) && len(tokens[0]) == 6:
+		if mm, _ := parseDigits(tokens[0][:2]); mm >= 1 && mm <= 12 {
+			m, y = tokens[0][:2], tokens[0][2:]
+		} else {
+			y, m = tokens[0][:4], tokens[0][4:]
+		}
+	case len(tokens) == 2 && !isDigitToken(tokens[0]) && isDigitToken(tokens[1]):
+		mm, ok := parseMonthName(tokens[0])
+		if !ok {
+			return 0, 0, newValidationError(CodeInvalidMonth, FieldMonth, ErrInvalidMonth)
+		}
+		m, y = fmt.Sprintf("%02d", mm), tokens[1]
+	case len(tokens) == 2 && isDigitToken(tokens[0]) && isDigitToken(tokens[1]):
+		if len(tokens[0]) == 4 {
+			y, m = tokens[0], tokens[1]
+		} else {
+			m, y = tokens[0], tokens[1]
+		}
+	default:
+		return 0, 0, newValidationError(CodeInvalidExpiry, FieldExpiry, ErrInvalidExpiry)
+	}
+
+	return parseExpiryFields(m, y, v.now())
+}
+
+// ParseExpiryYYMM parses an expiration date in the YYMM format of ISO 8583
+// data element 14 and of magnetic stripe tracks, e.g. "2712" for December
+// 2027. Two digit years are resolved like in ParseExpiry.
+func ParseExpiryYYMM(s string) (month, year int, err error) {
+	return defaultValidator.ParseExpiryYYMM(s)
+}
+
+// ParseExpiryYYMM parses an ISO 8583 expiration date like the ParseExpiryYYMM
+// function, resolving two digit years against the validator's clock
+func (v *Validator) ParseExpiryYYMM(s string) (month, year int, err error) {
+	yymm, err := normalizeDigits(s, FieldExpiry, false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(yymm) != 4 {
+		return 0, 0, newValidationError(CodeInvalidExpiry, FieldExpiry, ErrInvalidExpiry)
+	}
+
+	return parseExpiryFields(yymm[2:], yymm[:2], v.now())
+}
+
+// parseExpiryFields converts a one or two digit month and a two or four
+// digit year, expanding two digit years at the given time
+func parseExpiryFields(m, y string, now time.Time) (month, year int, err error) {
+	if len(m) < 1 || len(m) > 2 {
+		return 0, 0, newValidationError(CodeInvalidMonth, FieldMonth, ErrInvalidMonth)
+	}
+
+	month, err = parseDigits(m)
+	if err != nil || month < 1 || month > 12 {
+		return 0, 0, newValidationError(CodeInvalidMonth, FieldMonth, ErrInvalidMonth)
+	}
+
+	if len(y) != 2 && len(y) != 4 {
+		return 0, 0, newValidationError(CodeInvalidYear, FieldYear, ErrInvalidYear)
+	}
+
+	year, err = parseDigits(y)
+	if err != nil {
+		return 0, 0, newValidationError(CodeInvalidYear, FieldYear, ErrInvalidYear)
+	}
+
+	if len(y) == 2 {
+		year = expandYear(year, now)
+	}
+
+	return month, year, nil
+}
+
+// SetExpiry parses an expiration date with ParseExpiry and stores it in the
+// card's Month and Year, as "MM" and "YYYY". The card is left untouched if
+// the date is invalid.
+func (c *Card) SetExpiry(s string) error {
+	month, year, err := ParseExpiry(s)
+	if err != nil {
+		return err
+	}
+
+	c.Month, c.Year = fmt.Sprintf("%02d", month), fmt.Sprintf("%04d", year)
+	return nil
+}
+
+// Expiry is the month a card expires at. Cards are valid through the last
+// day of their expiration month.
+type Expiry struct {
+	Month time.Month
+	Year  int
+}
+
+// NewExpiry returns the expiry of the given month and four digit year
+func NewExpiry(month, year int) (Expiry, error) {
+	if month < 1 || month > 12 {
+		return Expiry{}, newValidationError(CodeInvalidMonth, FieldMonth, ErrInvalidMonth)
+	}
+
+	if year < 0 || year > 9999 {
+		return Expiry{}, newValidationError(CodeInvalidYear, FieldYear, ErrInvalidYear)
+	}
+
+	return Expiry{Month: time.Month(month), Year: year}, nil
+}
+
+// Expiry returns the card's expiration month. Two digit years are resolved
+// with the sliding century window described in ParseExpiry.
+func (c *Card) Expiry() (Expiry, error) {
+	return defaultValidator.Expiry(*c)
+}
+
+// Expiry returns the card's expiration month, resolving two digit years
+// against the validator's clock
+func (v *Validator) Expiry(c Card) (Expiry, error) {
+	if err := c.checkNumber(); err != nil {
+		return Expiry{}, err
+	}
+
+	cardYear, err := NormalizeYear(c.Year)
+	if err != nil {
+		return Expiry{}, err
+	}
+
+	cardMonth, err := NormalizeMonth(c.Month)
+	if err != nil {
+		return Expiry{}, err
+	}
+
+	year, err := parseDigits(cardYear)
+	if err != nil || len(cardYear) > 4 {
+		return Expiry{}, newValidationError(CodeInvalidYear, FieldYear, ErrInvalidYear)
+	}
+
+	if len(cardYear) < 3 {
+		year = expandYear(year, v.now())
+	}
+
+	month, err := parseDigits(cardMonth)
+	if err != nil {
+		return Expiry{}, newValidationError(CodeInvalidMonth, FieldMonth, ErrInvalidMonth)
+	}
+
+	return NewExpiry(month, year)
+}
+
+// months returns the number of months since year 0, for comparisons
+func (e Expiry) months() int {
+	return e.Year*12 + int(e.Month) - 1
+}
+
+// LastValidInstant returns the last instant the card is valid at in the given
+// location: the last nanosecond of its expiration month
+func (e Expiry) LastValidInstant(loc *time.Location) time.Time {
+	return time.Date(e.Year, e.Month+1, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+}
+
+// IsExpiredAt reports whether the card is expired at the given time, the end
+// of the expiration month being taken in the time's location
+func (e Expiry) IsExpiredAt(t time.Time) bool {
+	return t.After(e.LastValidInstant(t.Location()))
+}
+
+// MonthsRemaining returns the number of months from the given time's month
+// to the expiration month: 0 when the card expires this month, negative when
+// it is already expired
+func (e Expiry) MonthsRemaining(at time.Time) int {
+	return e.months() - Expiry{Month: at.Month(), Year: at.Year()}.months()
+}
+
+// Before reports whether the expiry is earlier than other
+func (e Expiry) Before(other Expiry) bool {
+	return e.months() < other.months()
+}
+
+// After reports whether the expiry is later than other
+func (e Expiry) After(other Expiry) bool {
+	return e.months() > other.months()
+}
+
+// String returns the expiry as MM/YYYY
+func (e Expiry) String() string {
+	return fmt.Sprintf("%02d/%04d", int(e.Month), e.Year)
+}
@@ -0,0 +1,171 @@
+package creditcard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseExpiry(t *testing.T) {
+	Convey("Should parse free-form expiration dates", t, func() {
+		v := NewValidator(WithClock(func() time.Time {
+			return time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+		}))
+
+		for _, input := range []string{
+			"12/27", "12 / 2027", "12-27", "12.2027", "1227", "122027",
+			"2027-12", "2027/12", "202712", "Dec 2027", "december 27", "DEC/27",
+			" １２/２７ ",
+		} {
+			month, year, err := v.ParseExpiry(input)
+			So(err, ShouldBeNil)
+			So(month, ShouldEqual, 12)
+			So(year, ShouldEqual, 2027)
+		}
+
+		Convey("Single digit months are accepted with a separator", func() {
+			month, year, err := v.ParseExpiry("1/30")
+			So(err, ShouldBeNil)
+			So(month, ShouldEqual, 1)
+			So(year, ShouldEqual, 2030)
+
+			month, _, err = v.ParseExpiry("Sept 2030")
+			So(err, ShouldBeNil)
+			So(month, ShouldEqual, 9)
+		})
+
+		Convey("ISO 8583 dates are parsed as YYMM", func() {
+			month, year, err := v.ParseExpiryYYMM("2712")
+			So(err, ShouldBeNil)
+			So(month, ShouldEqual, 12)
+			So(year, ShouldEqual, 2027)
+
+			_, _, err = v.ParseExpiryYYMM("27/12")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+		})
+
+		Convey("Invalid dates are rejected", func() {
+			_, _, err := v.ParseExpiry("13/27")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidMonth)
+
+			_, _, err = v.ParseExpiry("Foo 2027")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidMonth)
+
+			_, _, err = v.ParseExpiry("12/202")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidYear)
+
+			_, _, err = v.ParseExpiry("12/27/01")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidExpiry)
+			So(errors.Is(err, ErrInvalidExpiry), ShouldBeTrue)
+
+			_, _, err = v.ParseExpiry("")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidExpiry)
+
+			_, _, err = v.ParseExpiry("12_27")
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+		})
+
+		Convey("SetExpiry fills the card's month and year", func() {
+			card := Card{Month: "01", Year: "2001"}
+
+			So(card.SetExpiry("3/28"), ShouldBeNil)
+			So(card.Month, ShouldEqual, "03")
+			So(card.Year, ShouldEqual, "2028")
+
+			So(card.SetExpiry("00/28"), ShouldNotBeNil)
+			So(card.Month, ShouldEqual, "03")
+		})
+	})
+
+	Convey("Two digit years should follow a sliding century window", t, func() {
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		So(expandYear(6, now), ShouldEqual, 2006)
+		So(expandYear(5, now), ShouldEqual, 2105)
+		So(expandYear(99, now), ShouldEqual, 2099)
+
+		now = time.Date(2098, 1, 1, 0, 0, 0, 0, time.UTC)
+		So(expandYear(99, now), ShouldEqual, 2099)
+		So(expandYear(1, now), ShouldEqual, 2101)
+		So(expandYear(78, now), ShouldEqual, 2078)
+
+		Convey("Including in ValidateExpiration", func() {
+			v := NewValidator(WithClock(func() time.Time {
+				return time.Date(2099, 6, 1, 0, 0, 0, 0, time.UTC)
+			}))
+
+			card := Card{Month: "01", Year: "01"}
+			So(v.ValidateExpiration(card), ShouldBeNil)
+
+			card = Card{Month: "01", Year: "99"}
+			So(ErrorCodeOf(v.ValidateExpiration(card)), ShouldEqual, CodeExpired)
+		})
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	Convey("Should compute with expiration months", t, func() {
+		expiry, err := NewExpiry(2, 2028)
+		So(err, ShouldBeNil)
+		So(expiry.String(), ShouldEqual, "02/2028")
+
+		Convey("A card is valid through the end of its expiration month", func() {
+			So(expiry.IsExpiredAt(time.Date(2028, 2, 29, 23, 59, 59, 0, time.UTC)), ShouldBeFalse)
+			So(expiry.IsExpiredAt(time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(expiry.IsExpiredAt(time.Date(2027, 12, 1, 0, 0, 0, 0, time.UTC)), ShouldBeFalse)
+		})
+
+		Convey("The end of the month depends on the location", func() {
+			tokyo := time.FixedZone("JST", 9*3600)
+			last := expiry.LastValidInstant(tokyo)
+
+			So(last.Equal(time.Date(2028, 3, 1, 0, 0, 0, 0, tokyo).Add(-time.Nanosecond)), ShouldBeTrue)
+			So(last.Before(expiry.LastValidInstant(time.UTC)), ShouldBeTrue)
+
+			at := time.Date(2028, 2, 29, 16, 0, 0, 0, time.UTC)
+			So(expiry.IsExpiredAt(at), ShouldBeFalse)
+			So(expiry.IsExpiredAt(at.In(tokyo)), ShouldBeTrue)
+		})
+
+		Convey("Months remaining are counted from the given month", func() {
+			So(expiry.MonthsRemaining(time.Date(2028, 2, 15, 0, 0, 0, 0, time.UTC)), ShouldEqual, 0)
+			So(expiry.MonthsRemaining(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)), ShouldEqual, 16)
+			So(expiry.MonthsRemaining(time.Date(2028, 4, 1, 0, 0, 0, 0, time.UTC)), ShouldEqual, -2)
+		})
+
+		Convey("Expiries are ordered", func() {
+			later, _ := NewExpiry(1, 2029)
+			So(expiry.Before(later), ShouldBeTrue)
+			So(later.After(expiry), ShouldBeTrue)
+			So(expiry.After(expiry), ShouldBeFalse)
+		})
+
+		Convey("Invalid months and years are rejected", func() {
+			_, err := NewExpiry(0, 2028)
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidMonth)
+
+			_, err = NewExpiry(1, 10000)
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidYear)
+		})
+	})
+
+	Convey("Should get a card's expiry", t, func() {
+		v := NewValidator(WithClock(func() time.Time {
+			return time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+		}))
+
+		card := Card{Month: "2", Year: "28"}
+		expiry, err := v.Expiry(card)
+		So(err, ShouldBeNil)
+		So(expiry, ShouldResemble, Expiry{Month: time.February, Year: 2028})
+
+		card = Card{Month: "02", Year: "20288"}
+		_, err = v.Expiry(card)
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidYear)
+
+		card = Card{Month: "", Year: "2028"}
+		_, err = v.Expiry(card)
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidMonth)
+	})
+}
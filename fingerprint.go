@@ -0,0 +1,376 @@
+package creditcard
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minFingerprintKey is the minimum length of a fingerprint key, in bytes
+const minFingerprintKey = 16
+
+// Fingerprint identifies a card number without revealing it: it is the
+// HMAC-SHA256 of the normalized number under a versioned key, so that the
+// same number always has the same fingerprint under a given key, whatever its
+// formatting. Fingerprints are comparable and can be used as map keys.
+type Fingerprint struct {
+	Version uint32
+	Sum     [sha256.Size]byte
+}
+
+// String returns the fingerprint as "v<version>:<hex sum>"
+func (f Fingerprint) String() string {
+	return "v" + strconv.FormatUint(uint64(f.Version), 10) + ":" + hex.EncodeToString(f.Sum[:])
+}
+
+// ParseFingerprint parses a fingerprint returned by Fingerprint.String
+func ParseFingerprint(s string) (Fingerprint, error) {
+	version, sum, ok := strings.Cut(s, ":")
+	if !ok || !strings.HasPrefix(version, "v") {
+		return Fingerprint{}, fmt.Errorf("Invalid fingerprint %q", s)
+	}
+
+	v, err := strconv.ParseUint(version[1:], 10, 32)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("Invalid fingerprint %q", s)
+	}
+
+	f := Fingerprint{Version: uint32(v)}
+	if len(sum) != hex.EncodedLen(sha256.Size) {
+		return Fingerprint{}, fmt.Errorf("Invalid fingerprint %q", s)
+	}
+
+	if _, err := hex.Decode(f.Sum[:], []byte(sum)); err != nil {
+		return Fingerprint{}, fmt.Errorf("Invalid fingerprint %q", s)
+	}
+
+	return f, nil
+}
+
+// Fingerprinter computes card fingerprints with a keyring of versioned keys:
+// new fingerprints use the current key, while fingerprints of older keys can
+// still be computed to look cards up during a rotation. It is safe for
+// concurrent use.
+type Fingerprinter struct {
+	mu      sync.RWMutex
+	keys    map[uint32][]byte
+	current uint32
+}
+
+// NewFingerprinter returns a fingerprinter whose current key is key, with
+// the given version. The key must be at least 16 bytes long and is copied.
+func NewFingerprinter(version uint32, key []byte) (*Fingerprinter, error) {
+	f := &Fingerprinter{keys: map[uint32][]byte{}}
+	if err := f.Rotate(version, key); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// AddKey adds a key to the keyring without making it current, e.g. to
+// index cards under it before rotating. The key is copied.
+func (f *Fingerprinter) AddKey(version uint32, key []byte) error {
+	if len(key) < minFingerprintKey {
+		return fmt.Errorf("Fingerprint key %d is %d bytes long, must be at least %d", version, len(key), minFingerprintKey)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.keys[version]; ok {
+		return fmt.Errorf("Fingerprint key %d already exists", version)
+	}
+
+	f.keys[version] = append([]byte(nil), key...)
+	return nil
+}
+
+// Rotate makes the key with the given version current, adding it to the
+// keyring first when key is not nil. Older keys are kept until removed.
+func (f *Fingerprinter) Rotate(version uint32, key []byte) error {
+	if key != nil {
+		if err := f.AddKey(version, key); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.keys[version]; !ok {
+		return fmt.Errorf("Unknown fingerprint key %d", version)
+	}
+
+	f.current = version
+	return nil
+}
+
+// RemoveKey zeroizes and removes a key from the keyring. The current key
+// cannot be removed.
+func (f *Fingerprinter) RemoveKey(version uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if version == f.current {
+		return fmt.Errorf("Fingerprint key %d is current", version)
+	}
+
+	key, ok := f.keys[version]
+	if !ok {
+		return fmt.Errorf("Unknown fingerprint key %d", version)
+	}
+
+	zeroBytes(key)
+	delete(f.keys, version)
+	return nil
+}
+
+// Current returns the version of the current key
+func (f *Fingerprinter) Current() uint32 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.current
+}
+
+// Versions returns the versions of every key, in increasing order
+func (f *Fingerprinter) Versions() []uint32 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	versions := make([]uint32, 0, len(f.keys))
+	for version := range f.keys {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions
+}
+
+// Has reports whether the keyring holds a key with the given version
+func (f *Fingerprinter) Has(version uint32) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, ok := f.keys[version]
+	return ok
+}
+
+// Fingerprint returns the fingerprint of the card's number under the current key
+func (f *Fingerprinter) Fingerprint(c Card) (Fingerprint, error) {
+	return f.FingerprintWith(f.Current(), c)
+}
+
+// FingerprintWith returns the fingerprint of the card's number under the key
+// with the given version
+func (f *Fingerprinter) FingerprintWith(version uint32, c Card) (Fingerprint, error) {
+	number, err := fingerprintNumber(c)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	key, ok := f.keys[version]
+	if !ok {
+		return Fingerprint{}, fmt.Errorf("Unknown fingerprint key %d", version)
+	}
+
+	return fingerprint(version, key, number), nil
+}
+
+// Fingerprints returns the fingerprints of the card's number under every key,
+// in increasing version order
+func (f *Fingerprinter) Fingerprints(c Card) ([]Fingerprint, error) {
+	number, err := fingerprintNumber(c)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	fingerprints := make([]Fingerprint, 0, len(f.keys))
+	for version, key := range f.keys {
+		fingerprints = append(fingerprints, fingerprint(version, key, number))
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i].Version < fingerprints[j].Version })
+
+	return fingerprints, nil
+}
+
+// fingerprintNumber returns the normalized number of the card, failing when
+// it is empty, invalid or wiped
+func fingerprintNumber(c Card) (string, error) {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return "", err
+	}
+
+	if number == "" {
+		return "", newValidationError(CodeNumberTooShort, FieldNumber, ErrNumberTooShort)
+	}
+
+	return number, nil
+}
+
+func fingerprint(version uint32, key []byte, number string) Fingerprint {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(number))
+
+	f := Fingerprint{Version: version}
+	mac.Sum(f.Sum[:0])
+	return f
+}
+
+// FingerprintIndex groups accounts by the fingerprints of their cards, to find
+// out whether several accounts use the same card without storing its number.
+// Cards are indexed under every key of the fingerprinter at the time they are
+// added, so that they can still be looked up after a rotation. It is safe for
+// concurrent use.
+type FingerprintIndex struct {
+	fingerprinter *Fingerprinter
+	mu            sync.RWMutex
+	accounts      map[Fingerprint]map[string]struct{}
+}
+
+// NewFingerprintIndex returns an empty index using the given fingerprinter
+func NewFingerprintIndex(f *Fingerprinter) *FingerprintIndex {
+	return &FingerprintIndex{fingerprinter: f, accounts: map[Fingerprint]map[string]struct{}{}}
+}
+
+// Add indexes the card for the account and returns the other accounts using
+// the same card, sorted. Re-adding a card after adding a key indexes it under
+// that key too.
+func (x *FingerprintIndex) Add(account string, c Card) ([]string, error) {
+	fingerprints, err := x.fingerprinter.Fingerprints(c)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	others := x.lookup(fingerprints, account)
+	for _, f := range fingerprints {
+		if x.accounts[f] == nil {
+			x.accounts[f] = map[string]struct{}{}
+		}
+		x.accounts[f][account] = struct{}{}
+	}
+
+	return others, nil
+}
+
+// Remove removes the card from the account
+func (x *FingerprintIndex) Remove(account string, c Card) error {
+	fingerprints, err := x.fingerprinter.Fingerprints(c)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, f := range fingerprints {
+		x.remove(f, account)
+	}
+
+	return nil
+}
+
+// RemoveAccount removes every card of the account
+func (x *FingerprintIndex) RemoveAccount(account string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for f := range x.accounts {
+		x.remove(f, account)
+	}
+}
+
+func (x *FingerprintIndex) remove(f Fingerprint, account string) {
+	delete(x.accounts[f], account)
+	if len(x.accounts[f]) == 0 {
+		delete(x.accounts, f)
+	}
+}
+
+// Lookup returns the accounts using the card, sorted
+func (x *FingerprintIndex) Lookup(c Card) ([]string, error) {
+	fingerprints, err := x.fingerprinter.Fingerprints(c)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.lookup(fingerprints, ""), nil
+}
+
+// lookup returns the accounts indexed under any of the fingerprints, except
+// the given one, sorted. It must be called with the lock held.
+func (x *FingerprintIndex) lookup(fingerprints []Fingerprint, except string) []string {
+	seen := map[string]struct{}{}
+	var accounts []string
+
+	for _, f := range fingerprints {
+		for account := range x.accounts[f] {
+			if _, ok := seen[account]; ok || account == except {
+				continue
+			}
+			seen[account] = struct{}{}
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+
+	return accounts
+}
+
+// Accounts returns the accounts indexed under the fingerprint, sorted
+func (x *FingerprintIndex) Accounts(f Fingerprint) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.lookup([]Fingerprint{f}, "")
+}
+
+// Duplicates returns the groups of accounts sharing a card, under the
+// current key, keyed by fingerprint. Cards added before the current key was
+// added must be added again to be grouped.
+func (x *FingerprintIndex) Duplicates() map[Fingerprint][]string {
+	current := x.fingerprinter.Current()
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	duplicates := map[Fingerprint][]string{}
+	for f, accounts := range x.accounts {
+		if f.Version == current && len(accounts) > 1 {
+			duplicates[f] = x.lookup([]Fingerprint{f}, "")
+		}
+	}
+
+	return duplicates
+}
+
+// Prune removes the fingerprints of keys removed from the fingerprinter
+func (x *FingerprintIndex) Prune() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for f := range x.accounts {
+		if !x.fingerprinter.Has(f.Version) {
+			delete(x.accounts, f)
+		}
+	}
+}
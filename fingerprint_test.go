@@ -0,0 +1,183 @@
+package creditcard
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFingerprint(t *testing.T) {
+	key1 := bytes.Repeat([]byte{1}, 32)
+	key2 := bytes.Repeat([]byte{2}, 32)
+
+	Convey("Should fingerprint numbers whatever their formatting", t, func() {
+		f, err := NewFingerprinter(1, key1)
+		So(err, ShouldBeNil)
+
+		plain, err := f.Fingerprint(Card{Number: "4556974850403706"})
+		So(err, ShouldBeNil)
+		So(plain.Version, ShouldEqual, 1)
+
+		for _, number := range []string{"4556 9748 5040 3706", "4556-9748-5040-3706", " 4556.9748.5040.3706 ", "４５５６９７４８５０４０３７０６"} {
+			fingerprint, err := f.Fingerprint(Card{Number: number})
+			So(err, ShouldBeNil)
+			So(fingerprint, ShouldResemble, plain)
+		}
+
+		secure := Card{Number: "4556974850403706"}
+		secure.Secure()
+		fingerprint, err := f.Fingerprint(secure)
+		So(err, ShouldBeNil)
+		So(fingerprint, ShouldResemble, plain)
+
+		other, err := f.Fingerprint(Card{Number: "4556974850403714"})
+		So(err, ShouldBeNil)
+		So(other, ShouldNotResemble, plain)
+
+		Convey("Fingerprints depend on the key", func() {
+			g, err := NewFingerprinter(1, key2)
+			So(err, ShouldBeNil)
+			fingerprint, err := g.Fingerprint(Card{Number: "4556974850403706"})
+			So(err, ShouldBeNil)
+			So(fingerprint, ShouldNotResemble, plain)
+		})
+
+		Convey("Fingerprints can be parsed back", func() {
+			s := plain.String()
+			So(s, ShouldStartWith, "v1:")
+			So(len(s), ShouldEqual, 3+64)
+
+			parsed, err := ParseFingerprint(s)
+			So(err, ShouldBeNil)
+			So(parsed, ShouldResemble, plain)
+
+			for _, invalid := range []string{"", "v1", "1:" + s[3:], "vx:" + s[3:], "v1:" + s[4:], "v1:" + s[3:] + "00", "v1:zz" + s[5:]} {
+				_, err := ParseFingerprint(invalid)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
+	Convey("Should reject invalid numbers and keys", t, func() {
+		_, err := NewFingerprinter(1, []byte("short"))
+		So(err, ShouldNotBeNil)
+
+		f, _ := NewFingerprinter(1, key1)
+
+		_, err = f.Fingerprint(Card{Number: ""})
+		So(ErrorCodeOf(err), ShouldEqual, CodeNumberTooShort)
+		_, err = f.Fingerprint(Card{Number: "4556x974850403706"})
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+
+		wiped := Card{Number: "4556974850403706"}
+		wiped.Wipe()
+		_, err = f.Fingerprint(wiped)
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+
+		_, err = f.FingerprintWith(2, Card{Number: "4556974850403706"})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Should rotate keys", t, func() {
+		f, _ := NewFingerprinter(1, key1)
+		card := Card{Number: "4556974850403706"}
+		old, _ := f.Fingerprint(card)
+
+		So(f.AddKey(1, key2), ShouldNotBeNil)
+		So(f.Rotate(3, nil), ShouldNotBeNil)
+		So(f.Rotate(2, key2), ShouldBeNil)
+		So(f.Current(), ShouldEqual, 2)
+		So(f.Versions(), ShouldResemble, []uint32{1, 2})
+
+		current, _ := f.Fingerprint(card)
+		So(current.Version, ShouldEqual, 2)
+
+		all, err := f.Fingerprints(card)
+		So(err, ShouldBeNil)
+		So(all, ShouldResemble, []Fingerprint{old, current})
+
+		So(f.RemoveKey(2), ShouldNotBeNil)
+		So(f.RemoveKey(1), ShouldBeNil)
+		So(f.RemoveKey(1), ShouldNotBeNil)
+		So(f.Has(1), ShouldBeFalse)
+	})
+}
+
+func TestFingerprintIndex(t *testing.T) {
+	Convey("Should group accounts using the same card", t, func() {
+		f, _ := NewFingerprinter(1, bytes.Repeat([]byte{1}, 32))
+		index := NewFingerprintIndex(f)
+
+		others, err := index.Add("alice", Card{Number: "4556974850403706"})
+		So(err, ShouldBeNil)
+		So(others, ShouldBeEmpty)
+
+		others, err = index.Add("bob", Card{Number: "4556 9748 5040 3706"})
+		So(err, ShouldBeNil)
+		So(others, ShouldResemble, []string{"alice"})
+
+		_, err = index.Add("carol", Card{Number: "4556974850403714"})
+		So(err, ShouldBeNil)
+
+		accounts, err := index.Lookup(Card{Number: "4556-9748-5040-3706"})
+		So(err, ShouldBeNil)
+		So(accounts, ShouldResemble, []string{"alice", "bob"})
+
+		fingerprint, _ := f.Fingerprint(Card{Number: "4556974850403706"})
+		So(index.Accounts(fingerprint), ShouldResemble, []string{"alice", "bob"})
+		So(index.Duplicates(), ShouldResemble, map[Fingerprint][]string{fingerprint: {"alice", "bob"}})
+
+		Convey("Accounts can be removed", func() {
+			So(index.Remove("bob", Card{Number: "4556974850403706"}), ShouldBeNil)
+			So(index.Duplicates(), ShouldBeEmpty)
+
+			index.RemoveAccount("alice")
+			accounts, _ := index.Lookup(Card{Number: "4556974850403706"})
+			So(accounts, ShouldBeEmpty)
+		})
+
+		Convey("Cards can still be looked up after a key rotation", func() {
+			So(f.Rotate(2, bytes.Repeat([]byte{2}, 32)), ShouldBeNil)
+
+			others, err := index.Add("dave", Card{Number: "4556974850403706"})
+			So(err, ShouldBeNil)
+			So(others, ShouldResemble, []string{"alice", "bob"})
+
+			_, err = index.Add("alice", Card{Number: "4556974850403706"})
+			So(err, ShouldBeNil)
+
+			current, _ := f.Fingerprint(Card{Number: "4556974850403706"})
+			So(index.Duplicates(), ShouldResemble, map[Fingerprint][]string{current: {"alice", "dave"}})
+
+			So(f.RemoveKey(1), ShouldBeNil)
+			index.Prune()
+			So(index.Accounts(fingerprint), ShouldBeEmpty)
+
+			accounts, _ := index.Lookup(Card{Number: "4556974850403706"})
+			So(accounts, ShouldResemble, []string{"alice", "dave"})
+		})
+	})
+
+	Convey("Should be safe for concurrent use", t, func() {
+		f, _ := NewFingerprinter(1, bytes.Repeat([]byte{1}, 32))
+		index := NewFingerprintIndex(f)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				index.Add(fmt.Sprintf("account%02d", i), Card{Number: "4556974850403706"})
+				index.Lookup(Card{Number: "4556974850403706"})
+				index.Duplicates()
+			}(i)
+		}
+		wg.Wait()
+
+		accounts, _ := index.Lookup(Card{Number: "4556974850403706"})
+		So(len(accounts), ShouldEqual, 20)
+	})
+}
@@ -0,0 +1,118 @@
+package creditcard
+
+import "strings"
+
+// FormatOptions controls how FormatNumber renders a card number. Separator
+// goes between groups of digits and defaults to a space. When MaskChar is
+// set, every digit but the first ShowFirst and the last ShowLast is replaced
+// with it, as MaskNumber does: showing more digits than PCI DSS allows is
+// refused. Policy, if set, masks the number instead.
+type FormatOptions struct {
+	Separator           string
+	MaskChar            rune
+	ShowFirst, ShowLast int
+	Policy              *MaskPolicy
+}
+
+// FormatNumber groups the digits of a card number the way its company prints
+// them, e.g. 4-6-5 for American Express and 4-4-4-4 for most 16 digits cards.
+// Numbers are normalized first, see NormalizeNumber.
+func FormatNumber(number string, opts FormatOptions) (string, error) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return "", err
+	}
+
+	var groups []int
+	if s, err := DefaultRegistry.detect(number); err == nil {
+		groups = s.groupsFor(len(number))
+	}
+	if groups == nil {
+		groups = defaultGroups(len(number))
+	}
+
+	if opts.Policy != nil {
+		number, err = MaskNumber(number, *opts.Policy)
+		if err != nil {
+			return "", err
+		}
+	} else if opts.MaskChar != 0 {
+		number, err = MaskNumber(number, MaskPolicy{First: opts.ShowFirst, Last: opts.ShowLast, MaskChar: opts.MaskChar})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	separator := opts.Separator
+	if separator == "" {
+		separator = " "
+	}
+
+	return groupDigits(number, groups, separator), nil
+}
+
+// FormatNumber groups the digits of the card's number, see FormatNumber
+func (c *Card) FormatNumber(opts FormatOptions) (string, error) {
+	if err := c.checkNumber(); err != nil {
+		return "", err
+	}
+
+	return FormatNumber(c.number(), opts)
+}
+
+// groupsFor returns the grouping of the scheme for numbers of n digits, if any
+func (s Scheme) groupsFor(n int) []int {
+	for _, groups := range s.Groups {
+		sum := 0
+		for _, g := range groups {
+			sum += g
+		}
+
+		if sum == n {
+			return groups
+		}
+	}
+
+	return nil
+}
+
+// defaultGroups splits n digits in groups of four, the last one taking the rest
+func defaultGroups(n int) []int {
+	var groups []int
+	for ; n > 4; n -= 4 {
+		groups = append(groups, 4)
+	}
+
+	if n > 0 {
+		groups = append(groups, n)
+	}
+
+	return groups
+}
+
+// groupDigits joins the groups of characters of s with the separator
+func groupDigits(s string, groups []int, separator string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	pos := 0
+
+	for _, g := range groups {
+		if pos >= len(runes) {
+			break
+		}
+
+		if pos > 0 {
+			b.WriteString(separator)
+		}
+
+		end := pos + g
+		if end > len(runes) {
+			end = len(runes)
+		}
+
+		b.WriteString(string(runes[pos:end]))
+		pos = end
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package creditcard
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormatNumber(t *testing.T) {
+	Convey("Should group digits the way the company prints them", t, func() {
+		examples := map[string]string{
+			"4242424242424242":    "4242 4242 4242 4242",
+			"378282246310005":     "3782 822463 10005",
+			"30569309025904":      "3056 930902 5904",
+			"6200000000000000005": "6200 0000 0000 0000 005",
+			"4222222222222":       "4222 2222 2222 2",
+			"1234":                "1234",
+		}
+
+		for number, expected := range examples {
+			card := Card{Number: number}
+			formatted, err := card.FormatNumber(FormatOptions{})
+
+			So(err, ShouldBeNil)
+			So(formatted, ShouldEqual, expected)
+		}
+	})
+
+	Convey("Should normalize the number first", t, func() {
+		formatted, err := FormatNumber("3782-822463-10005", FormatOptions{Separator: "-"})
+
+		So(err, ShouldBeNil)
+		So(formatted, ShouldEqual, "3782-822463-10005")
+
+		_, err = FormatNumber("3782x822463", FormatOptions{})
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+	})
+
+	Convey("Should combine with masking", t, func() {
+		formatted, err := FormatNumber("378282246310005", FormatOptions{MaskChar: '•', ShowLast: 4})
+
+		So(err, ShouldBeNil)
+		So(formatted, ShouldEqual, "•••• •••••• •0005")
+
+		formatted, err = FormatNumber("4242424242424242", FormatOptions{Separator: " - ", MaskChar: '*', ShowFirst: 6, ShowLast: 4})
+
+		So(err, ShouldBeNil)
+		So(formatted, ShouldEqual, "4242 - 42** - **** - 4242")
+	})
+
+	Convey("Should never show more digits than PCI DSS allows", t, func() {
+		for _, opts := range []FormatOptions{
+			{MaskChar: '*', ShowFirst: 16},
+			{MaskChar: '*', ShowFirst: 9, ShowLast: 4},
+			{MaskChar: '*', ShowLast: 5},
+			{MaskChar: '*', ShowFirst: -1},
+		} {
+			_, err := FormatNumber("4242424242424242", opts)
+			So(ErrorCodeOf(err), ShouldEqual, CodeMaskPolicyViolation)
+		}
+
+		_, err := FormatNumber("4242424242424", FormatOptions{MaskChar: '*', ShowFirst: 8, ShowLast: 4})
+		So(ErrorCodeOf(err), ShouldEqual, CodeMaskPolicyViolation)
+	})
+}
@@ -0,0 +1,187 @@
+package creditcard
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/big"
+	"strings"
+)
+
+// Format-preserving encryption of decimal numeral strings, FF1 and FF3-1 of
+// NIST SP 800-38G, with a radix of 10. Lengths and tweaks are checked by the
+// callers.
+
+var bigTen = big.NewInt(10)
+
+// num returns the value of the decimal numeral string x
+func num(x string) *big.Int {
+	n, _ := new(big.Int).SetString(x, 10)
+	if n == nil {
+		n = new(big.Int)
+	}
+
+	return n
+}
+
+// str returns n as a decimal numeral string of m digits
+func str(n *big.Int, m int) string {
+	s := n.String()
+	if len(s) < m {
+		s = strings.Repeat("0", m-len(s)) + s
+	}
+
+	return s
+}
+
+// pow10 returns 10^m
+func pow10(m int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(m)), nil)
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b)
+}
+
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
+}
+
+// ff1 encrypts, or decrypts, the decimal numeral string x with FF1 under the
+// AES block and tweak
+func ff1(block cipher.Block, tweak []byte, x string, decrypt bool) string {
+	n := len(x)
+	u, v := n/2, n-n/2
+	a, b := x[:u], x[u:]
+
+	// b is ceil(ceil(v*log2(10))/8); 10^v is never a power of 2, so its
+	// bit length is ceil(v*log2(10))
+	bLen := (pow10(v).BitLen() + 7) / 8
+	d := 4*((bLen+3)/4) + 4
+
+	p := []byte{1, 2, 1, 0, 0, 10, 10, byte(u % 256), 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(p[8:], uint32(n))
+	binary.BigEndian.PutUint32(p[12:], uint32(len(tweak)))
+
+	pad := ((-len(tweak)-bLen-1)%16 + 16) % 16
+	q := make([]byte, len(tweak)+pad+1+bLen)
+	copy(q, tweak)
+
+	prf := func(round int, numeral string) *big.Int {
+		q[len(tweak)+pad] = byte(round)
+		num(numeral).FillBytes(q[len(q)-bLen:])
+
+		r := make([]byte, aesBlockSize)
+		block.Encrypt(r, xorBlock(r, p))
+		for i := 0; i < len(q); i += aesBlockSize {
+			block.Encrypt(r, xorBlock(r, q[i:i+aesBlockSize]))
+		}
+
+		s := append([]byte(nil), r...)
+		for j := 1; len(s) < d; j++ {
+			counter := make([]byte, aesBlockSize)
+			binary.BigEndian.PutUint64(counter[8:], uint64(j))
+			block.Encrypt(counter, xorBlock(r, counter))
+			s = append(s, counter...)
+		}
+
+		return new(big.Int).SetBytes(s[:d])
+	}
+
+	for i := 0; i < 10; i++ {
+		round := i
+		if decrypt {
+			round = 9 - i
+		}
+
+		m := u
+		if round%2 == 1 {
+			m = v
+		}
+
+		if !decrypt {
+			y := prf(round, b)
+			c := new(big.Int).Add(num(a), y)
+			a, b = b, str(c.Mod(c, pow10(m)), m)
+		} else {
+			y := prf(round, a)
+			c := new(big.Int).Sub(num(b), y)
+			a, b = str(c.Mod(c, pow10(m)), m), a
+		}
+	}
+
+	return a + b
+}
+
+// ff3 encrypts, or decrypts, the decimal numeral string x with FF3 under the
+// AES block, keyed with the reversed key, and the 64 bit tweak
+func ff3(block cipher.Block, tweak [8]byte, x string, decrypt bool) string {
+	n := len(x)
+	u, v := (n+1)/2, n-(n+1)/2
+	a, b := x[:u], x[u:]
+
+	prf := func(round int, numeral string) *big.Int {
+		w := tweak[:4]
+		if round%2 == 0 {
+			w = tweak[4:]
+		}
+
+		p := make([]byte, aesBlockSize)
+		copy(p, w)
+		p[3] ^= byte(round)
+		num(reverse(numeral)).FillBytes(p[4:])
+
+		s := make([]byte, aesBlockSize)
+		block.Encrypt(s, reverseBytes(p))
+		return new(big.Int).SetBytes(reverseBytes(s))
+	}
+
+	for i := 0; i < 8; i++ {
+		round := i
+		if decrypt {
+			round = 7 - i
+		}
+
+		m := u
+		if round%2 == 1 {
+			m = v
+		}
+
+		if !decrypt {
+			y := prf(round, b)
+			c := new(big.Int).Add(num(reverse(a)), y)
+			a, b = b, reverse(str(c.Mod(c, pow10(m)), m))
+		} else {
+			y := prf(round, a)
+			c := new(big.Int).Sub(num(reverse(b)), y)
+			a, b = reverse(str(c.Mod(c, pow10(m)), m)), a
+		}
+	}
+
+	return a + b
+}
+
+// ff31Tweak expands a 56 bit FF3-1 tweak into the 64 bit tweak of FF3
+func ff31Tweak(t [7]byte) [8]byte {
+	return [8]byte{t[0], t[1], t[2], t[3] & 0xf0, t[4], t[5], t[6], t[3] << 4}
+}
+
+const aesBlockSize = 16
+
+func xorBlock(a, b []byte) []byte {
+	r := make([]byte, aesBlockSize)
+	for i := range r {
+		r[i] = a[i] ^ b[i]
+	}
+
+	return r
+}
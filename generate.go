@@ -0,0 +1,150 @@
+package creditcard
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxGenerateAttempts bounds the number of candidates Generate draws before
+// giving up on a scheme whose ranges are all shadowed by other schemes
+const maxGenerateAttempts = 1000
+
+// LuhnCheckDigit returns the digit to append to a partial card number for it
+// to pass the Luhn algorithm. The partial number is normalized first, see NormalizeNumber.
+func LuhnCheckDigit(partial string) (int, error) {
+	number, err := NormalizeNumber(partial)
+	if err != nil {
+		return 0, err
+	}
+
+	if number == "" {
+		return 0, newValidationError(CodeNumberTooShort, FieldNumber, ErrNumberTooShort)
+	}
+
+	return luhnCheckDigit(number), nil
+}
+
+func luhnCheckDigit(number string) int {
+	var sum int
+	alternate := true
+
+	for i := len(number) - 1; i > -1; i-- {
+		mod := int(number[i] - '0')
+		if alternate {
+			mod *= 2
+			if mod > 9 {
+				mod = (mod % 10) + 1
+			}
+		}
+
+		alternate = !alternate
+
+		sum += mod
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// Generator creates card numbers for the schemes of its Registry, the
+// DefaultRegistry when nil. Generated numbers have a prefix and length of
+// the scheme, a valid check digit, are detected as the scheme and are never
+// in a registered test card catalog. The zero value draws random numbers; it is safe
+// for concurrent use.
+type Generator struct {
+	Registry *Registry
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewGenerator returns a generator drawing numbers deterministically from the seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed))}
+}
+
+var defaultGenerator = NewGenerator(time.Now().UnixNano())
+
+// GenerateNumber returns a random card number for the scheme with the given
+// short name in the DefaultRegistry
+func GenerateNumber(short string) (string, error) {
+	return defaultGenerator.Generate(short)
+}
+
+// Generate returns a card number for the scheme with the given short name
+func (g *Generator) Generate(short string) (string, error) {
+	return g.GenerateLength(short, 0)
+}
+
+// GenerateLength returns a card number of the given length for the scheme
+// with the given short name. A zero length picks one of the scheme's lengths.
+func (g *Generator) GenerateLength(short string, length int) (string, error) {
+	registry := g.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	s, ok := registry.Scheme(short)
+	if !ok {
+		return "", newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+	}
+
+	lengths := s.Lengths
+	if length > 0 {
+		lengths = []int{length}
+	} else if len(lengths) == 0 {
+		lengths = []int{16}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.rand == nil {
+		g.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		r := s.Ranges[g.rand.Intn(len(s.Ranges))]
+		if r.CoBadgeOnly {
+			continue
+		}
+
+		var candidates []int
+		for _, l := range lengths {
+			if l > r.Digits && (r.MinLength == 0 || l >= r.MinLength) && (r.MaxLength == 0 || l <= r.MaxLength) {
+				candidates = append(candidates, l)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		number := g.candidate(s, r, candidates[g.rand.Intn(len(candidates))])
+		if detected, err := registry.detect(number); err == nil && detected.Company.Short == short && !isKnownTestNumber(number) {
+			return number, nil
+		}
+	}
+
+	return "", fmt.Errorf("Could not generate a number for scheme %q, its ranges are shadowed by other schemes or do not fit its lengths", short)
+}
+
+func (g *Generator) candidate(s Scheme, r IINRange, length int) string {
+	var b strings.Builder
+
+	prefix := r.Low + g.rand.Intn(r.High-r.Low+1)
+	fmt.Fprintf(&b, "%0*d", r.Digits, prefix)
+
+	for b.Len() < length-1 {
+		b.WriteByte(byte('0' + g.rand.Intn(10)))
+	}
+
+	if s.Checksum == ChecksumNone {
+		b.WriteByte(byte('0' + g.rand.Intn(10)))
+	} else {
+		b.WriteByte(byte('0' + luhnCheckDigit(b.String())))
+	}
+
+	return b.String()
+}
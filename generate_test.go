@@ -0,0 +1,102 @@
+package creditcard
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLuhnCheckDigit(t *testing.T) {
+	Convey("Should compute the Luhn check digit of a partial number", t, func() {
+		examples := map[string]int{
+			"424242424242424": 2,
+			"455697485040370": 6,
+			"37828224631000":  5,
+			"7992739871":      3,
+			"0":               0,
+		}
+
+		for partial, expected := range examples {
+			digit, err := LuhnCheckDigit(partial)
+
+			So(err, ShouldBeNil)
+			So(digit, ShouldEqual, expected)
+		}
+
+		digit, err := LuhnCheckDigit("4242 4242 4242 424")
+		So(err, ShouldBeNil)
+		So(digit, ShouldEqual, 2)
+
+		_, err = LuhnCheckDigit("")
+		So(err, ShouldNotBeNil)
+
+		_, err = LuhnCheckDigit("42x")
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+	})
+}
+
+func TestGenerator(t *testing.T) {
+	Convey("Should generate valid numbers for every built-in scheme", t, func() {
+		generator := NewGenerator(42)
+
+		for _, s := range BuiltinSchemes() {
+			for i := 0; i < 50; i++ {
+				number, err := generator.Generate(s.Company.Short)
+				So(err, ShouldBeNil)
+
+				card := Card{Number: number}
+				company, err := card.MethodValidate()
+				So(err, ShouldBeNil)
+				So(company, ShouldResemble, s.Company)
+				So(card.ValidateNumber(), ShouldBeTrue)
+				So(card.ValidateLength(), ShouldBeNil)
+				So(isKnownTestNumber(number), ShouldBeFalse)
+			}
+		}
+	})
+
+	Convey("Should be deterministic for a given seed", t, func() {
+		first, second := NewGenerator(7), NewGenerator(7)
+
+		for i := 0; i < 20; i++ {
+			a, _ := first.Generate("visa")
+			b, _ := second.Generate("visa")
+			So(a, ShouldEqual, b)
+		}
+	})
+
+	Convey("Should generate distinct numbers", t, func() {
+		seen := map[string]bool{}
+		for i := 0; i < 1000; i++ {
+			number, err := GenerateNumber("mastercard")
+			So(err, ShouldBeNil)
+			seen[number] = true
+		}
+
+		So(len(seen), ShouldBeGreaterThan, 990)
+	})
+
+	Convey("Should generate numbers of a given length", t, func() {
+		number, err := NewGenerator(1).GenerateLength("visa", 19)
+
+		So(err, ShouldBeNil)
+		So(len(number), ShouldEqual, 19)
+	})
+
+	Convey("Should fail for unknown or unreachable schemes", t, func() {
+		_, err := NewGenerator(1).Generate("unknown")
+		So(err, ShouldNotBeNil)
+
+		registry := NewRegistry()
+		So(registry.Register(Scheme{Company: Company{"wide", "Wide"}, Ranges: []IINRange{Prefix("4")}, Priority: 1}), ShouldBeNil)
+		So(registry.Register(Scheme{Company: Company{"shadowed", "Shadowed"}, Ranges: []IINRange{Prefix("42")}, Priority: 2}), ShouldBeNil)
+
+		generator := &Generator{Registry: registry}
+		_, err = generator.Generate("shadowed")
+		So(err, ShouldNotBeNil)
+
+		number, err := generator.Generate("wide")
+		So(err, ShouldBeNil)
+		So(number[0], ShouldEqual, '4')
+	})
+}
@@ -1,8 +1,11 @@
 module github.com/durango/go-credit-card
 
-go 1.17
+go 1.21
 
-require github.com/smartystreets/goconvey v1.6.4
+require (
+	github.com/smartystreets/goconvey v1.6.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/gopherjs/gopherjs v0.0.0-20181017120253-0766667cb4d1 // indirect
@@ -1,10 +1,2 @@
 // Package creditcard provides methods for validating credit cards
 package creditcard
-
-import "time"
-
-var timeNowCaller = time.Now
-
-func resetMocks() {
-	timeNowCaller = time.Now
-}
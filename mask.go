@@ -0,0 +1,169 @@
+package creditcard
+
+import "strings"
+
+// MaskPolicy tells which digits of a card number may be shown. First and
+// Last are the number of leading and trailing digits revealed, the others
+// being replaced with MaskChar, '*' by default. A Template, if set, takes
+// precedence: it must have as many characters as the number has digits,
+// '#' revealing the digit at that position and any other character being
+// output in place of the digit.
+//
+// Whatever the policy, no more than PCI DSS allows is ever revealed: the
+// last four digits, and the first six, or the first eight for numbers of 16
+// digits or more. Policies revealing more, or the whole number, are refused.
+type MaskPolicy struct {
+	Name        string
+	First, Last int
+	Template    string
+	MaskChar    rune
+}
+
+// Named masking policies
+var (
+	// MaskFirstSixLastFour is the classic PCI DSS truncation
+	MaskFirstSixLastFour = MaskPolicy{Name: "first6-last4", First: 6, Last: 4}
+
+	// MaskFirstEightLastFour is the PCI DSS truncation for 8 digit BINs,
+	// which is only allowed for numbers of 16 digits or more
+	MaskFirstEightLastFour = MaskPolicy{Name: "first8-last4", First: 8, Last: 4}
+
+	// MaskLastFour only reveals the last four digits
+	MaskLastFour = MaskPolicy{Name: "last4", Last: 4}
+)
+
+// MaskTemplate returns a policy masking numbers with the given template,
+// e.g. "######******####"
+func MaskTemplate(template string) MaskPolicy {
+	return MaskPolicy{Name: "template", Template: template}
+}
+
+// maxRevealed returns how many leading and trailing digits of a number of n
+// digits may be revealed
+func maxRevealed(n int) (int, int) {
+	if n >= 16 {
+		return 8, 4
+	}
+
+	return 6, 4
+}
+
+// layout returns, for each digit of a number of n digits, the character to
+// output in its place, or 0 when the digit is revealed
+func (p MaskPolicy) layout(n int) ([]rune, error) {
+	maxFirst, maxLast := maxRevealed(n)
+	out := make([]rune, n)
+	revealed := 0
+
+	if p.Template != "" {
+		template := []rune(p.Template)
+		if len(template) != n {
+			return nil, newValidationError(CodeMaskPolicyViolation, FieldNumber, ErrMaskPolicyViolation)
+		}
+
+		for i, r := range template {
+			if r != '#' {
+				out[i] = r
+				continue
+			}
+
+			if i >= maxFirst && i < n-maxLast {
+				return nil, newValidationError(CodeMaskPolicyViolation, FieldNumber, ErrMaskPolicyViolation)
+			}
+			revealed++
+		}
+	} else {
+		if p.First < 0 || p.Last < 0 || p.First > maxFirst || p.Last > maxLast {
+			return nil, newValidationError(CodeMaskPolicyViolation, FieldNumber, ErrMaskPolicyViolation)
+		}
+
+		mask := p.MaskChar
+		if mask == 0 {
+			mask = '*'
+		}
+
+		for i := range out {
+			if i < p.First || i >= n-p.Last {
+				revealed++
+			} else {
+				out[i] = mask
+			}
+		}
+	}
+
+	if revealed >= n {
+		return nil, newValidationError(CodeMaskPolicyViolation, FieldNumber, ErrMaskPolicyViolation)
+	}
+
+	return out, nil
+}
+
+// MaskNumber returns the card number with the digits the policy does not
+// allow to show masked. Numbers are normalized first, see NormalizeNumber.
+func MaskNumber(number string, policy MaskPolicy) (string, error) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return "", err
+	}
+
+	layout, err := policy.layout(len(number))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, r := range layout {
+		if r == 0 {
+			b.WriteByte(number[i])
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// TruncateNumber returns the leading and trailing digits of the card number
+// the policy allows to store. Digits a template reveals past the leading or
+// trailing run are dropped.
+func TruncateNumber(number string, policy MaskPolicy) (string, string, error) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return "", "", err
+	}
+
+	layout, err := policy.layout(len(number))
+	if err != nil {
+		return "", "", err
+	}
+
+	first := 0
+	for first < len(layout) && layout[first] == 0 {
+		first++
+	}
+
+	last := len(layout)
+	for last > first && layout[last-1] == 0 {
+		last--
+	}
+
+	return number[:first], number[last:], nil
+}
+
+// Mask returns the card's number masked according to the policy, see MaskNumber
+func (c *Card) Mask(policy MaskPolicy) (string, error) {
+	if err := c.checkNumber(); err != nil {
+		return "", err
+	}
+
+	return MaskNumber(c.number(), policy)
+}
+
+// Truncate returns the digits of the card's number the policy allows to store, see TruncateNumber
+func (c *Card) Truncate(policy MaskPolicy) (string, string, error) {
+	if err := c.checkNumber(); err != nil {
+		return "", "", err
+	}
+
+	return TruncateNumber(c.number(), policy)
+}
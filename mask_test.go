@@ -0,0 +1,94 @@
+package creditcard
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMask(t *testing.T) {
+	Convey("Should mask numbers with named policies", t, func() {
+		card := Card{Number: "4242 4242 4242 4242"}
+
+		masked, err := card.Mask(MaskFirstSixLastFour)
+		So(err, ShouldBeNil)
+		So(masked, ShouldEqual, "424242******4242")
+
+		masked, err = card.Mask(MaskFirstEightLastFour)
+		So(err, ShouldBeNil)
+		So(masked, ShouldEqual, "42424242****4242")
+
+		masked, err = card.Mask(MaskLastFour)
+		So(err, ShouldBeNil)
+		So(masked, ShouldEqual, "************4242")
+
+		policy := MaskLastFour
+		policy.MaskChar = '•'
+		masked, err = card.Mask(policy)
+		So(err, ShouldBeNil)
+		So(masked, ShouldEqual, "••••••••••••4242")
+	})
+
+	Convey("Should mask numbers with templates", t, func() {
+		masked, err := MaskNumber("4242424242424242", MaskTemplate("######XXXXXX####"))
+		So(err, ShouldBeNil)
+		So(masked, ShouldEqual, "424242XXXXXX4242")
+
+		_, err = MaskNumber("378282246310005", MaskTemplate("######XXXXXX####"))
+		So(ErrorCodeOf(err), ShouldEqual, CodeMaskPolicyViolation)
+	})
+
+	Convey("Should refuse to reveal more than allowed for the number's length", t, func() {
+		Convey("First eight digits of a 15 digits number", func() {
+			_, err := MaskNumber("378282246310005", MaskFirstEightLastFour)
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeMaskPolicyViolation)
+			So(errors.Is(err, ErrMaskPolicyViolation), ShouldBeTrue)
+		})
+
+		Convey("Too many trailing digits", func() {
+			_, err := MaskNumber("4242424242424242", MaskPolicy{Last: 5})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Templates revealing middle digits", func() {
+			_, err := MaskNumber("4242424242424242", MaskTemplate("******#*********"))
+			So(err, ShouldBeNil)
+
+			_, err = MaskNumber("4242424242424242", MaskTemplate("*********#******"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("The whole number", func() {
+			_, err := MaskNumber("4242424242", MaskFirstSixLastFour)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Should truncate numbers", t, func() {
+		card := Card{Number: "4242424242424242"}
+
+		first, last, err := card.Truncate(MaskFirstSixLastFour)
+		So(err, ShouldBeNil)
+		So(first, ShouldEqual, "424242")
+		So(last, ShouldEqual, "4242")
+
+		first, last, err = TruncateNumber("4242424242424242", MaskTemplate("####**#*****####"))
+		So(err, ShouldBeNil)
+		So(first, ShouldEqual, "4242")
+		So(last, ShouldEqual, "4242")
+
+		_, _, err = TruncateNumber("378282246310005", MaskFirstEightLastFour)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Should format with a masking policy", t, func() {
+		formatted, err := FormatNumber("378282246310005", FormatOptions{Policy: &MaskFirstSixLastFour})
+		So(err, ShouldBeNil)
+		So(formatted, ShouldEqual, "3782 82**** *0005")
+
+		_, err = FormatNumber("378282246310005", FormatOptions{Policy: &MaskFirstEightLastFour})
+		So(err, ShouldNotBeNil)
+	})
+}
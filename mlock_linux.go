@@ -0,0 +1,32 @@
+//go:build linux
+
+package creditcard
+
+import "syscall"
+
+// lockedAlloc returns a zeroed buffer of at least n bytes in its own
+// page-aligned anonymous mapping, locked in memory. Such a mapping is never
+// shared with other values nor moved by the garbage collector, so that it can
+// be unlocked and released on its own with lockedFree.
+func lockedAlloc(n int) ([]byte, error) {
+	page := syscall.Getpagesize()
+	size := (n + page - 1) / page * page
+
+	mem, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Mlock(mem); err != nil {
+		syscall.Munmap(mem)
+		return nil, err
+	}
+
+	return mem, nil
+}
+
+// lockedFree unlocks and releases a buffer returned by lockedAlloc
+func lockedFree(mem []byte) {
+	syscall.Munlock(mem)
+	syscall.Munmap(mem)
+}
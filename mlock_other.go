@@ -0,0 +1,11 @@
+//go:build !linux
+
+package creditcard
+
+import "errors"
+
+func lockedAlloc(n int) ([]byte, error) {
+	return nil, errors.New("Locking memory is only supported on Linux")
+}
+
+func lockedFree(mem []byte) {}
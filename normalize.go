@@ -0,0 +1,150 @@
+package creditcard
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CharacterError reports a character that is neither a digit nor an allowed
+// separator. Position is the index of the offending character, counted in
+// runes from the start of the input.
+type CharacterError struct {
+	Position int
+	Char     rune
+}
+
+func (e *CharacterError) Error() string {
+	return fmt.Sprintf("Invalid character %q at position %d", e.Char, e.Position)
+}
+
+// Unwrap returns ErrInvalidCharacter
+func (e *CharacterError) Unwrap() error {
+	return ErrInvalidCharacter
+}
+
+// NormalizeNumber returns the canonical form of a card number: its digits
+// only, in ASCII. Spaces, dashes and dots are allowed as separators and
+// dropped, and Unicode decimal digits, such as full-width ones, are converted.
+// Any other character is reported as a ValidationError wrapping a CharacterError.
+func NormalizeNumber(number string) (string, error) {
+	return normalizeDigits(number, FieldNumber, true)
+}
+
+// NormalizeCVV returns the canonical form of a security code: surrounding
+// spaces are dropped and Unicode decimal digits converted to ASCII
+func NormalizeCVV(cvv string) (string, error) {
+	return normalizeDigits(cvv, FieldCVV, false)
+}
+
+// NormalizeMonth returns the canonical form of an expiration month, see NormalizeCVV
+func NormalizeMonth(month string) (string, error) {
+	return normalizeDigits(month, FieldMonth, false)
+}
+
+// NormalizeYear returns the canonical form of an expiration year, see NormalizeCVV
+func NormalizeYear(year string) (string, error) {
+	return normalizeDigits(year, FieldYear, false)
+}
+
+func normalizeDigits(s string, field Field, separators bool) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	// surrounding spaces are always allowed, inner ones only as separators
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	offset := len([]rune(s)) - len([]rune(trimmed))
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+
+	position := offset
+	for _, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case unicode.IsDigit(r):
+			b.WriteByte(byte('0' + digitValue(r)))
+		case separators && isSeparator(r):
+		default:
+			return "", newValidationError(CodeInvalidCharacter, field, &CharacterError{Position: position, Char: r})
+		}
+		position++
+	}
+
+	return b.String(), nil
+}
+
+func isSeparator(r rune) bool {
+	return r == '.' || unicode.IsSpace(r) || unicode.Is(unicode.Pd, r)
+}
+
+// digitValue returns the value of a Unicode decimal digit. Decimal digits
+// come in contiguous runs from zero to nine, so the value is the distance to
+// the start of the run in the unicode.Nd table.
+func digitValue(r rune) int {
+	for _, rng := range unicode.Nd.R16 {
+		if r >= rune(rng.Lo) && r <= rune(rng.Hi) {
+			return int(r-rune(rng.Lo)) % 10
+		}
+	}
+
+	for _, rng := range unicode.Nd.R32 {
+		if r >= rune(rng.Lo) && r <= rune(rng.Hi) {
+			return int(r-rune(rng.Lo)) % 10
+		}
+	}
+
+	return 0
+}
+
+// Normalize replaces the card's number, CVV, month and year with their
+// canonical forms, leaving the card untouched if any of them is invalid
+func (c *Card) Normalize() error {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkCVV(); err != nil {
+		return err
+	}
+
+	cvv, err := NormalizeCVV(c.cvv())
+	if err != nil {
+		return err
+	}
+
+	month, err := NormalizeMonth(c.Month)
+	if err != nil {
+		return err
+	}
+
+	year, err := NormalizeYear(c.Year)
+	if err != nil {
+		return err
+	}
+
+	if c.SecureNumber != nil {
+		c.SecureNumber.replace([]byte(number))
+	} else {
+		c.Number = number
+	}
+
+	if c.SecureCvv != nil {
+		c.SecureCvv.replace([]byte(cvv))
+	} else {
+		c.Cvv = cvv
+	}
+
+	c.Month, c.Year = month, year
+	return nil
+}
+
+// NormalizedNumber returns the canonical form of the card's number, see
+// NormalizeNumber. It fails with ErrWiped once the card is wiped.
+func (c *Card) NormalizedNumber() (string, error) {
+	if err := c.checkNumber(); err != nil {
+		return "", err
+	}
+
+	return NormalizeNumber(c.number())
+}
@@ -0,0 +1,100 @@
+package creditcard
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalize(t *testing.T) {
+	Convey("Should normalize card numbers", t, func() {
+		Convey("Dropping separators", func() {
+			number, err := NormalizeNumber(" 4242 4242-4242.4242\n")
+
+			So(err, ShouldBeNil)
+			So(number, ShouldEqual, "4242424242424242")
+		})
+
+		Convey("Converting Unicode digits", func() {
+			number, err := NormalizeNumber("４２４２ ４２４２ ٤٢٤٢ 4242")
+
+			So(err, ShouldBeNil)
+			So(number, ShouldEqual, "4242424242424242")
+		})
+
+		Convey("Rejecting other characters with their position", func() {
+			_, err := NormalizeNumber("4242 42x2")
+
+			var cErr *CharacterError
+			So(errors.As(err, &cErr), ShouldBeTrue)
+			So(cErr.Position, ShouldEqual, 7)
+			So(cErr.Char, ShouldEqual, 'x')
+			So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+			So(errors.Is(err, ErrInvalidCharacter), ShouldBeTrue)
+		})
+
+		Convey("Counting positions in characters, leading spaces included", func() {
+			_, err := NormalizeNumber("  ４２a")
+
+			var cErr *CharacterError
+			So(errors.As(err, &cErr), ShouldBeTrue)
+			So(cErr.Position, ShouldEqual, 4)
+		})
+	})
+
+	Convey("Should not allow separators within CVVs and expiration fields", t, func() {
+		cvv, err := NormalizeCVV(" １２３ ")
+		So(err, ShouldBeNil)
+		So(cvv, ShouldEqual, "123")
+
+		_, err = NormalizeCVV("1 23")
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+
+		_, err = NormalizeMonth("1-2")
+		So(err, ShouldNotBeNil)
+
+		var vErr *ValidationError
+		_, err = NormalizeYear("20x7")
+		So(errors.As(err, &vErr), ShouldBeTrue)
+		So(vErr.Field, ShouldEqual, FieldYear)
+	})
+
+	Convey("Card methods should use the normalized number", t, func() {
+		card := Card{Number: "4556 9748-5040 3706\n", Cvv: "１２３", Month: " 12", Year: "2099 "}
+
+		So(card.Validate(), ShouldBeNil)
+		So(card.ValidateNumber(), ShouldBeTrue)
+
+		company, err := card.MethodValidate()
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "visa")
+
+		lastFour, err := card.LastFour()
+		So(err, ShouldBeNil)
+		So(lastFour, ShouldEqual, "3706")
+
+		Convey("and normalize it in place", func() {
+			So(card.Normalize(), ShouldBeNil)
+			So(card.Number, ShouldEqual, "4556974850403706")
+			So(card.Cvv, ShouldEqual, "123")
+			So(card.Month, ShouldEqual, "12")
+			So(card.Year, ShouldEqual, "2099")
+		})
+
+		Convey("test numbers should still be recognized", func() {
+			card := Card{Number: "4242-4242-4242-4242", Cvv: "123", Month: "12", Year: "2099"}
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeTestNumber)
+		})
+
+		Convey("invalid characters should not be scored as zeros", func() {
+			card := Card{Number: "4556974850403706x", Cvv: "123", Month: "12", Year: "2099"}
+			So(card.ValidateNumber(), ShouldBeFalse)
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeInvalidCharacter)
+
+			normalized := card
+			So(normalized.Normalize(), ShouldNotBeNil)
+			So(normalized, ShouldResemble, card)
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package creditcard
+
+// Check is one of the checks run by validation, combined into a set with |
+type Check uint
+
+// Checks that ValidateOptions.Skip can turn off
+const (
+	// CheckExpiration checks the card is not expired
+	CheckExpiration Check = 1 << iota
+	// CheckCVV checks the CVV against the card's company, it can be skipped
+	// for card-on-file charges where no CVV is collected
+	CheckCVV
+	// CheckTestNumber looks the number up in the test card catalogs
+	CheckTestNumber
+	// CheckChecksum checks the number's check digit
+	CheckChecksum
+	// CheckLength checks the number's length, generic and for its company
+	CheckLength
+)
+
+// ValidateOptions configures ValidateWith, ValidateAllWith and Validators.
+// The zero value validates like Validate without arguments.
+type ValidateOptions struct {
+	// AllowTestNumbers lets the test cards of TestCatalogs go through
+	AllowTestNumbers bool
+
+	// TestCatalogs names the catalogs whose cards count as test cards,
+	// DefaultTestCatalogs when empty
+	TestCatalogs []string
+
+	// AcceptedBrands restricts validation to the companies with these short
+	// names, any company being accepted when empty
+	AcceptedBrands []string
+
+	// RequireKnownBrand rejects numbers of an unknown company
+	RequireKnownBrand bool
+
+	// StrictDigits only accepts numbers made of ASCII digits, without
+	// separators nor Unicode digits
+	StrictDigits bool
+
+	// Strict turns on both StrictDigits and RequireKnownBrand
+	Strict bool
+
+	// MaskPolicy, when set, adds the card's number masked with it to the
+	// errors about the number, see ValidationError.Number
+	MaskPolicy *MaskPolicy
+
+	// Skip turns off the given checks
+	Skip Check
+
+	// RequireCVV rejects cards without a CVV, even for the companies which do
+	// not always print one. The CVV is checked even when CheckCVV is skipped.
+	RequireCVV bool
+
+	// MinExpiryMonths and MaxExpiryMonths bound how many months past the
+	// current one cards can expire at, MaxExpiryMonths being unbounded when zero
+	MinExpiryMonths, MaxExpiryMonths int
+}
+
+func (o ValidateOptions) testCatalogs() ([]string, error) {
+	if len(o.TestCatalogs) == 0 {
+		return DefaultTestCatalogs(), nil
+	}
+
+	return o.TestCatalogs, checkTestCatalogs(o.TestCatalogs)
+}
+
+func (o ValidateOptions) requireKnownBrand() bool {
+	return o.RequireKnownBrand || o.Strict
+}
+
+func (o ValidateOptions) strictDigits() bool {
+	return o.StrictDigits || o.Strict
+}
+
+func (o ValidateOptions) accepts(short string) bool {
+	for _, accepted := range o.AcceptedBrands {
+		if accepted == short {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o ValidateOptions) runs(check Check) bool {
+	return o.Skip&check == 0
+}
@@ -0,0 +1,158 @@
+package creditcard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AcceptancePolicy declares which cards a merchant accepts: their companies,
+// how long they must remain valid, whether test cards go through and whether
+// a CVV is required. It is usually loaded from a configuration file, see
+// ParsePolicyJSON and ParsePolicyYAML, and applied with WithPolicy.
+type AcceptancePolicy struct {
+	// AcceptedBrands are the short names of the accepted companies, any
+	// company being accepted when empty
+	AcceptedBrands []string `json:"accepted_brands" yaml:"accepted_brands"`
+
+	// MinExpiryMonths is how many months past the current one cards must
+	// remain valid, e.g. 1 to refuse cards expiring this month
+	MinExpiryMonths int `json:"min_expiry_months" yaml:"min_expiry_months"`
+
+	// MaxExpiryMonths is how many months past the current one cards can
+	// expire at, unbounded when zero
+	MaxExpiryMonths int `json:"max_expiry_months" yaml:"max_expiry_months"`
+
+	// AllowTestCards lets the test cards of TestCatalogs go through
+	AllowTestCards bool `json:"allow_test_cards" yaml:"allow_test_cards"`
+
+	// TestCatalogs names the catalogs whose cards count as test cards,
+	// DefaultTestCatalogs when empty
+	TestCatalogs []string `json:"test_catalogs" yaml:"test_catalogs"`
+
+	// RequireCVV refuses cards without a CVV, even for the companies which
+	// do not always print one
+	RequireCVV bool `json:"require_cvv" yaml:"require_cvv"`
+}
+
+// ParsePolicyJSON reads an acceptance policy from a JSON object, e.g.
+//
+//	{"accepted_brands": ["visa", "mastercard"], "min_expiry_months": 1, "require_cvv": true}
+//
+// Its accepted brands must be registered in the DefaultRegistry.
+func ParsePolicyJSON(r io.Reader) (AcceptancePolicy, error) {
+	return DefaultRegistry.ParsePolicyJSON(r)
+}
+
+// ParsePolicyYAML reads an acceptance policy from YAML, with the same keys
+// as ParsePolicyJSON
+func ParsePolicyYAML(r io.Reader) (AcceptancePolicy, error) {
+	return DefaultRegistry.ParsePolicyYAML(r)
+}
+
+// ParsePolicyJSON reads an acceptance policy like ParsePolicyJSON, its
+// accepted brands being registered in the registry
+func (r *Registry) ParsePolicyJSON(reader io.Reader) (AcceptancePolicy, error) {
+	var p *AcceptancePolicy
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&p); err == io.EOF || (err == nil && p == nil) {
+		return AcceptancePolicy{}, fmt.Errorf("Invalid acceptance policy: empty document")
+	} else if err != nil {
+		return AcceptancePolicy{}, fmt.Errorf("Invalid acceptance policy: %v", err)
+	}
+
+	return p.checked(r)
+}
+
+// ParsePolicyYAML reads an acceptance policy like ParsePolicyYAML, its
+// accepted brands being registered in the registry
+func (r *Registry) ParsePolicyYAML(reader io.Reader) (AcceptancePolicy, error) {
+	var p *AcceptancePolicy
+
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err == io.EOF || (err == nil && p == nil) {
+		return AcceptancePolicy{}, fmt.Errorf("Invalid acceptance policy: empty document")
+	} else if err != nil {
+		return AcceptancePolicy{}, fmt.Errorf("Invalid acceptance policy: %v", err)
+	}
+
+	return p.checked(r)
+}
+
+// checked returns the parsed policy if it passes Check, the zero policy
+// otherwise
+func (p *AcceptancePolicy) checked(r *Registry) (AcceptancePolicy, error) {
+	if err := p.Check(r); err != nil {
+		return AcceptancePolicy{}, err
+	}
+
+	return *p, nil
+}
+
+// Check makes sure the policy is consistent and only accepts brands
+// registered in the registry
+func (p AcceptancePolicy) Check(r *Registry) error {
+	if p.MinExpiryMonths < 0 || p.MaxExpiryMonths < 0 {
+		return fmt.Errorf("Invalid acceptance policy: negative expiry horizon")
+	}
+
+	if p.MaxExpiryMonths > 0 && p.MinExpiryMonths > p.MaxExpiryMonths {
+		return fmt.Errorf("Invalid acceptance policy: min_expiry_months %d is above max_expiry_months %d", p.MinExpiryMonths, p.MaxExpiryMonths)
+	}
+
+	for _, short := range p.AcceptedBrands {
+		if _, ok := r.Scheme(short); !ok {
+			return fmt.Errorf("Invalid acceptance policy: unknown brand %q", short)
+		}
+	}
+
+	if err := checkTestCatalogs(p.TestCatalogs); err != nil {
+		return fmt.Errorf("Invalid acceptance policy: %v", err)
+	}
+
+	return nil
+}
+
+// Options returns the validation options enforcing the policy
+func (p AcceptancePolicy) Options() ValidateOptions {
+	return ValidateOptions{
+		AllowTestNumbers: p.AllowTestCards,
+		TestCatalogs:     append([]string(nil), p.TestCatalogs...),
+		AcceptedBrands:   append([]string(nil), p.AcceptedBrands...),
+		MinExpiryMonths:  p.MinExpiryMonths,
+		MaxExpiryMonths:  p.MaxExpiryMonths,
+		RequireCVV:       p.RequireCVV,
+	}
+}
+
+// WithPolicy makes the validator enforce the acceptance policy. It replaces
+// the test card, accepted brands, expiry horizon and CVV requirement set by
+// the options before it, e.g. WithTestCards, even where the policy leaves
+// them unset; the options after it override the policy. Other options, such
+// as WithClock or WithSkip, are kept.
+func WithPolicy(p AcceptancePolicy) Option {
+	return func(v *Validator) {
+		v.opts.AllowTestNumbers = p.AllowTestCards
+		v.opts.TestCatalogs = append([]string(nil), p.TestCatalogs...)
+		v.opts.AcceptedBrands = append([]string(nil), p.AcceptedBrands...)
+		v.opts.MinExpiryMonths = p.MinExpiryMonths
+		v.opts.MaxExpiryMonths = p.MaxExpiryMonths
+		v.opts.RequireCVV = p.RequireCVV
+	}
+}
+
+// Validate validates the card like Card.Validate, enforcing the policy
+func (p AcceptancePolicy) Validate(c Card) error {
+	return NewValidator(WithPolicy(p)).Validate(c)
+}
+
+// MethodValidate returns the company of the card like Card.MethodValidate,
+// failing with ErrBrandNotAccepted when the policy does not accept it
+func (p AcceptancePolicy) MethodValidate(c Card) (Company, error) {
+	return NewValidator(WithPolicy(p)).MethodValidate(c)
+}
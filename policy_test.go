@@ -0,0 +1,193 @@
+package creditcard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAcceptancePolicy(t *testing.T) {
+	Convey("Should load policies from JSON and YAML", t, func() {
+		fromJSON, err := ParsePolicyJSON(strings.NewReader(`{
+			"accepted_brands": ["elo", "hipercard", "cabal", "naranja"],
+			"min_expiry_months": 1,
+			"max_expiry_months": 120,
+			"allow_test_cards": true,
+			"test_catalogs": ["adyen"],
+			"require_cvv": true
+		}`))
+		So(err, ShouldBeNil)
+
+		fromYAML, err := ParsePolicyYAML(strings.NewReader(`
+accepted_brands: [elo, hipercard, cabal, naranja]
+min_expiry_months: 1
+max_expiry_months: 120
+allow_test_cards: true
+test_catalogs:
+  - adyen
+require_cvv: true
+`))
+		So(err, ShouldBeNil)
+		So(fromYAML, ShouldResemble, fromJSON)
+		So(fromJSON.AcceptedBrands, ShouldResemble, []string{"elo", "hipercard", "cabal", "naranja"})
+		So(fromJSON.Options().RequireCVV, ShouldBeTrue)
+
+		acceptAll, err := ParsePolicyYAML(strings.NewReader("{}"))
+		So(err, ShouldBeNil)
+		So(acceptAll.Options(), ShouldResemble, ValidateOptions{})
+
+		Convey("Invalid policies are rejected", func() {
+			_, err := ParsePolicyJSON(strings.NewReader(`{"accepted_brand": ["visa"]}`))
+			So(err, ShouldNotBeNil)
+
+			_, err = ParsePolicyYAML(strings.NewReader("require_cvv: maybe"))
+			So(err, ShouldNotBeNil)
+
+			_, err = ParsePolicyYAML(strings.NewReader("min_expiry_months: 12\nmax_expiry_months: 6"))
+			So(err, ShouldNotBeNil)
+
+			_, err = ParsePolicyJSON(strings.NewReader(`{"test_catalogs": ["nope"]}`))
+			So(err, ShouldNotBeNil)
+
+			for _, empty := range []string{"", "  \n\t\n", "# no policy\n", "---\n", "~"} {
+				_, err = ParsePolicyYAML(strings.NewReader(empty))
+				So(err, ShouldNotBeNil)
+			}
+
+			for _, empty := range []string{"", "  \n", "null"} {
+				policy, err := ParsePolicyJSON(strings.NewReader(empty))
+				So(err, ShouldNotBeNil)
+				So(policy, ShouldResemble, AcceptancePolicy{})
+			}
+
+			policy, err := ParsePolicyJSON(strings.NewReader(`{"accepted_brands": ["visa"], "min_expiry_months": -1}`))
+			So(err, ShouldNotBeNil)
+			So(policy, ShouldResemble, AcceptancePolicy{})
+
+			policy, err = ParsePolicyYAML(strings.NewReader("accepted_brands: [vias]"))
+			So(err, ShouldNotBeNil)
+			So(policy, ShouldResemble, AcceptancePolicy{})
+		})
+
+		Convey("Unknown brands are rejected at load time", func() {
+			_, err := ParsePolicyYAML(strings.NewReader("accepted_brands: [visa, mastercrad]"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"mastercrad"`)
+
+			_, err = ParsePolicyJSON(strings.NewReader(`{"accepted_brands": ["amex", "vias"]}`))
+			So(err, ShouldNotBeNil)
+
+			registry := DefaultRegistry.Layer()
+			So(registry.Register(Scheme{
+				Company:   Company{"private", "Private Label"},
+				Ranges:    []IINRange{Prefix("990000")},
+				Lengths:   []int{16},
+				CVVLength: 3,
+				Checksum:  ChecksumNone,
+			}), ShouldBeNil)
+
+			policy, err := registry.ParsePolicyYAML(strings.NewReader("accepted_brands: [private, visa]"))
+			So(err, ShouldBeNil)
+			So(policy.AcceptedBrands, ShouldResemble, []string{"private", "visa"})
+
+			_, err = ParsePolicyYAML(strings.NewReader("accepted_brands: [private]"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Should enforce the accepted brands", t, func() {
+		policy := AcceptancePolicy{AcceptedBrands: []string{"visa", "mastercard"}}
+
+		amex := Card{Number: "378734493671000", Cvv: "1111", Month: "02", Year: "2099"}
+		err := policy.Validate(amex)
+		So(ErrorCodeOf(err), ShouldEqual, CodeBrandNotAccepted)
+		So(errors.Is(err, ErrBrandNotAccepted), ShouldBeTrue)
+
+		_, err = policy.MethodValidate(amex)
+		So(errors.Is(err, ErrBrandNotAccepted), ShouldBeTrue)
+
+		visa := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		So(policy.Validate(visa), ShouldBeNil)
+
+		company, err := policy.MethodValidate(visa)
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "visa")
+
+		Convey("Co-badged cards get their accepted company", func() {
+			card := Card{Number: "4571000000000001"}
+
+			company, err := AcceptancePolicy{AcceptedBrands: []string{"visa"}}.MethodValidate(card)
+			So(err, ShouldBeNil)
+			So(company.Short, ShouldEqual, "visa")
+
+			company, err = AcceptancePolicy{AcceptedBrands: []string{"dankort"}}.MethodValidate(card)
+			So(err, ShouldBeNil)
+			So(company.Short, ShouldEqual, "dankort")
+
+			company, _ = card.MethodValidate()
+			So(company.Short, ShouldEqual, "visa")
+		})
+
+		Convey("Overlapping ranges are not co-badges", func() {
+			maestro := Card{Number: "5018000000000009", Cvv: "111", Month: "02", Year: "2099"}
+			_, err := AcceptancePolicy{AcceptedBrands: []string{"aura"}}.MethodValidate(maestro)
+			So(errors.Is(err, ErrBrandNotAccepted), ShouldBeTrue)
+
+			cabal := Card{Number: "6271700000000004", Cvv: "111", Month: "02", Year: "2099"}
+			err = AcceptancePolicy{AcceptedBrands: []string{"china unionpay"}}.Validate(cabal)
+			So(ErrorCodeOf(err), ShouldEqual, CodeBrandNotAccepted)
+		})
+	})
+
+	Convey("Should enforce the expiry horizon", t, func() {
+		policy := AcceptancePolicy{MinExpiryMonths: 1, MaxExpiryMonths: 60}
+		clock := WithClock(func() time.Time {
+			return time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+		})
+		v := NewValidator(clock, WithPolicy(policy))
+
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "10", Year: "2026"}
+		So(NewValidator(clock).Validate(card), ShouldBeNil)
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeExpiryOutOfRange)
+
+		card.Month = "11"
+		So(v.Validate(card), ShouldBeNil)
+
+		card.Year = "2031"
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeExpiryOutOfRange)
+
+		card.Month = "10"
+		So(v.Validate(card), ShouldBeNil)
+	})
+
+	Convey("Should enforce the test card policy and the CVV", t, func() {
+		card := Card{Number: "4111111145551142", Month: "02", Year: "2099", CvvPresence: CVVNotProvided}
+
+		So(card.ValidateWith(ValidateOptions{Skip: CheckCVV}), ShouldBeNil)
+		So(ErrorCodeOf(AcceptancePolicy{TestCatalogs: []string{"adyen"}}.Validate(card)), ShouldEqual, CodeTestNumber)
+		So(AcceptancePolicy{TestCatalogs: []string{"adyen"}, AllowTestCards: true}.Validate(card), ShouldBeNil)
+
+		v := NewValidator(WithSkip(CheckCVV), WithPolicy(AcceptancePolicy{RequireCVV: true}))
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeCVVRequired)
+
+		card = Card{Number: "4556974850403706", Month: "02", Year: "2099"}
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeCVVRequired)
+
+		card.Cvv = "111"
+		So(v.Validate(card), ShouldBeNil)
+	})
+
+	Convey("Should replace the options given before the policy", t, func() {
+		card := Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+		policy := AcceptancePolicy{AcceptedBrands: []string{"visa"}}
+
+		v := NewValidator(WithTestCards(true), WithPolicy(policy))
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeTestNumber)
+
+		v = NewValidator(WithPolicy(policy), WithTestCards(true))
+		So(v.Validate(card), ShouldBeNil)
+	})
+}
@@ -0,0 +1,215 @@
+package creditcard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces sensitive values which cannot be masked
+const redacted = "[REDACTED]"
+
+// redactedNumber returns the card's number masked with MaskLastFour, or
+// redacted when it cannot be masked
+func (c Card) redactedNumber() string {
+	if c.Wiped() {
+		return "[WIPED]"
+	}
+
+	number := c.number()
+	if number == "" {
+		return ""
+	}
+
+	if masked, err := MaskNumber(number, MaskLastFour); err == nil {
+		return masked
+	}
+
+	return redacted
+}
+
+func (c Card) redactedCVV() string {
+	if c.wiped || c.SecureCvv.Wiped() {
+		return "[WIPED]"
+	}
+
+	if c.cvv() == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// String returns the card with its number masked and its CVV redacted
+func (c Card) String() string {
+	return fmt.Sprintf("{Number:%s Cvv:%s Month:%s Year:%s Company:%s}",
+		c.redactedNumber(), c.redactedCVV(), c.Month, c.Year, c.Company.Short)
+}
+
+// GoString returns the card as Go syntax, with its number masked and its
+// CVV redacted
+func (c Card) GoString() string {
+	return fmt.Sprintf("creditcard.Card{Number:%q, Cvv:%q, Month:%q, Year:%q, Company:%#v}",
+		c.redactedNumber(), c.redactedCVV(), c.Month, c.Year, c.Company)
+}
+
+// Format prints the card like String for every verb, so that no verb or
+// flag can print its number or CVV; %#v prints it like GoString and %q quotes it.
+// Use Unredacted to print the full card.
+func (c Card) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, c.GoString())
+	case verb == 'q':
+		fmt.Fprint(f, strconv.Quote(c.String()))
+	default:
+		fmt.Fprint(f, c.String())
+	}
+}
+
+// LogValue logs the card as a group with its number masked, without its CVV
+func (c Card) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("number", c.redactedNumber()),
+		slog.String("month", c.Month),
+		slog.String("year", c.Year),
+		slog.String("company", c.Company.Short),
+	)
+}
+
+// redactedCard is the JSON representation of a Card, with the keys Card has
+// always been encoded with but without its CVV
+type redactedCard struct {
+	Number, Month, Year string
+	Company             Company
+}
+
+// unredactedCard is the JSON representation of an UnredactedCard
+type unredactedCard struct {
+	Number, Cvv, Month, Year string
+	Company                  Company
+}
+
+// MarshalJSON encodes the card with its number masked, without its CVV.
+// Use Unredacted to encode the full card.
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedCard{
+		Number:  c.redactedNumber(),
+		Month:   c.Month,
+		Year:    c.Year,
+		Company: c.Company,
+	})
+}
+
+// UnmarshalJSON decodes a card encoded as an UnredactedCard. Encoded Cards
+// cannot be decoded since their number is masked.
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var decoded unredactedCard
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if isRedacted(decoded.Number) || isRedacted(decoded.Cvv) {
+		return errors.New("Cannot decode a redacted credit card")
+	}
+
+	*c = Card{
+		Number:  decoded.Number,
+		Cvv:     decoded.Cvv,
+		Month:   decoded.Month,
+		Year:    decoded.Year,
+		Company: decoded.Company,
+	}
+	return nil
+}
+
+// isRedacted reports whether the value was masked or redacted when encoded
+func isRedacted(value string) bool {
+	return value == redacted || value == "[WIPED]" || strings.ContainsRune(value, '*')
+}
+
+// MarshalText encodes the card like String
+func (c Card) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnredactedCard is a Card printed, logged and encoded with its full number
+// and CVV, for the rare code paths which must serialize them
+type UnredactedCard Card
+
+// Unredacted returns the card as an UnredactedCard, explicitly opting in to
+// printing, logging and encoding its full number and CVV. Secured values are
+// revealed into Number and Cvv.
+func (c Card) Unredacted() UnredactedCard {
+	c.Number, c.Cvv = c.number(), c.cvv()
+	c.SecureNumber, c.SecureCvv = nil, nil
+	return UnredactedCard(c)
+}
+
+// MarshalJSON encodes the card with the same keys as Card, its CVV included,
+// so that it can be decoded back into a Card
+func (c UnredactedCard) MarshalJSON() ([]byte, error) {
+	return json.Marshal(unredactedCard{
+		Number:  c.Number,
+		Cvv:     c.Cvv,
+		Month:   c.Month,
+		Year:    c.Year,
+		Company: c.Company,
+	})
+}
+
+// String returns the company's long name, or its short one when unset
+func (c Company) String() string {
+	if c.Long == "" {
+		return c.Short
+	}
+
+	return c.Long
+}
+
+// LogValue logs the company as a group of its short and long names
+func (c Company) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("short", c.Short),
+		slog.String("long", c.Long),
+	)
+}
+
+// String returns the result with its number masked. Like the other printing
+// methods of ValidationResult, it has a value receiver so that copies of a
+// result never print its number either.
+func (r ValidationResult) String() string {
+	company, _ := r.Company()
+	return fmt.Sprintf("{Number:%s Company:%s Valid:%t}", r.masked, company.Short, r.Valid())
+}
+
+// GoString returns the result like String
+func (r ValidationResult) GoString() string {
+	return "creditcard.ValidationResult" + r.String()
+}
+
+// Format prints the result like String for every verb, %#v printing it like
+// GoString and %q quoting it
+func (r ValidationResult) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, r.GoString())
+	case verb == 'q':
+		fmt.Fprint(f, strconv.Quote(r.String()))
+	default:
+		fmt.Fprint(f, r.String())
+	}
+}
+
+// LogValue logs the result with its number masked
+func (r ValidationResult) LogValue() slog.Value {
+	company, _ := r.Company()
+	return slog.GroupValue(
+		slog.String("number", r.masked),
+		slog.String("company", company.Short),
+		slog.Bool("valid", r.Valid()),
+	)
+}
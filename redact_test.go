@@ -0,0 +1,112 @@
+package creditcard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedaction(t *testing.T) {
+	card := Card{Number: "4556974850403706", Cvv: "123", Month: "02", Year: "2099", Company: Company{"visa", "Visa"}}
+
+	Convey("Printing a card should never show its number nor CVV", t, func() {
+		So(card.String(), ShouldEqual, "{Number:************3706 Cvv:[REDACTED] Month:02 Year:2099 Company:visa}")
+
+		for _, format := range []string{"%v", "%+v", "%s", "%q", "%#v", "%x", "%d", "%10.3v"} {
+			out := fmt.Sprintf(format, card)
+			So(out, ShouldNotContainSubstring, "4556974850403706")
+			So(out, ShouldNotContainSubstring, "123")
+
+			out = fmt.Sprintf(format, &card)
+			So(out, ShouldNotContainSubstring, "4556974850403706")
+		}
+
+		So(fmt.Sprintf("%#v", card), ShouldEqual, `creditcard.Card{Number:"************3706", Cvv:"[REDACTED]", Month:"02", Year:"2099", Company:creditcard.Company{Short:"visa", Long:"Visa"}}`)
+		So(fmt.Sprint([]Card{card}), ShouldNotContainSubstring, "4556974850403706")
+
+		Convey("Numbers which cannot be masked are redacted", func() {
+			short := Card{Number: "4242"}
+			So(short.String(), ShouldEqual, "{Number:[REDACTED] Cvv: Month: Year: Company:}")
+		})
+	})
+
+	Convey("Logging a card should mask its number and drop its CVV", t, func() {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Info("charge", "card", card, "company", card.Company)
+
+		So(buf.String(), ShouldContainSubstring, `"card":{"number":"************3706","month":"02","year":"2099","company":"visa"}`)
+		So(buf.String(), ShouldContainSubstring, `"company":{"short":"visa","long":"Visa"}`)
+		So(buf.String(), ShouldNotContainSubstring, "123")
+	})
+
+	Convey("Encoding a card should mask its number and drop its CVV", t, func() {
+		data, err := json.Marshal(card)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"Number":"************3706","Month":"02","Year":"2099","Company":{"Short":"visa","Long":"Visa"}}`)
+
+		var decoded Card
+		So(json.Unmarshal(data, &decoded), ShouldNotBeNil)
+		So(decoded.Number, ShouldEqual, "")
+
+		data, err = json.Marshal(map[string]*Card{"card": &card})
+		So(err, ShouldBeNil)
+		So(string(data), ShouldNotContainSubstring, "4556974850403706")
+
+		text, err := card.MarshalText()
+		So(err, ShouldBeNil)
+		So(string(text), ShouldEqual, card.String())
+	})
+
+	Convey("The full card should only be shown when opted in", t, func() {
+		full := card.Unredacted()
+
+		So(fmt.Sprintf("%v", full), ShouldContainSubstring, "4556974850403706 123")
+
+		data, err := json.Marshal(full)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"Number":"4556974850403706","Cvv":"123","Month":"02","Year":"2099","Company":{"Short":"visa","Long":"Visa"}}`)
+
+		secured := card
+		secured.Secure()
+		data, err = json.Marshal(secured.Unredacted())
+		So(err, ShouldBeNil)
+		So(string(data), ShouldNotContainSubstring, "Secure")
+		So(string(data), ShouldNotContainSubstring, "CvvPresence")
+
+		var decoded Card
+		So(json.Unmarshal(data, &decoded), ShouldBeNil)
+		So(decoded, ShouldResemble, card)
+	})
+
+	Convey("Companies and results should print safely", t, func() {
+		So(card.Company.String(), ShouldEqual, "Visa")
+		So(Company{Short: "acme"}.String(), ShouldEqual, "acme")
+
+		result := card.Evaluate()
+		So(fmt.Sprint(result), ShouldEqual, "{Number:************3706 Company:visa Valid:true}")
+
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("validated", "result", result)
+		So(strings.Contains(buf.String(), "4556974850403706"), ShouldBeFalse)
+
+		Convey("Even when copied", func() {
+			copied := *result
+			for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+				So(fmt.Sprintf(format, copied), ShouldNotContainSubstring, "4556974850403706")
+				So(fmt.Sprintf(format, result), ShouldNotContainSubstring, "4556974850403706")
+			}
+			So(fmt.Sprintf("%#v", copied), ShouldEqual, "creditcard.ValidationResult{Number:************3706 Company:visa Valid:true}")
+
+			buf.Reset()
+			slog.New(slog.NewJSONHandler(&buf, nil)).Info("validated", "result", copied)
+			So(buf.String(), ShouldNotContainSubstring, "4556974850403706")
+			So(buf.String(), ShouldContainSubstring, "************3706")
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package creditcard
+
+import "errors"
+
+// Report lists every problem found on a card by ValidateAll.
+// Errors make the card invalid, warnings are informational only.
+// TestCard is set when the number is a test card of the selected catalogs.
+// Failures which are not ValidationErrors are reported without Code or Field.
+type Report struct {
+	Company  Company
+	TestCard *TestCard
+	Errors   []*ValidationError
+	Warnings []*ValidationError
+}
+
+// Valid reports whether no errors were found
+func (r *Report) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the first error of the report, or nil when the card is valid
+func (r *Report) Err() error {
+	if r.Valid() {
+		return nil
+	}
+
+	return r.Errors[0]
+}
+
+// FieldErrors returns the errors reported for the given field
+func (r *Report) FieldErrors(field Field) []*ValidationError {
+	var errs []*ValidationError
+	for _, err := range r.Errors {
+		if err.Field == field {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (r *Report) addError(err error) {
+	if err != nil {
+		r.Errors = append(r.Errors, asValidationError(err))
+	}
+}
+
+func (r *Report) addWarning(err error) {
+	if err != nil {
+		r.Warnings = append(r.Warnings, asValidationError(err))
+	}
+}
+
+// asValidationError returns the error as a ValidationError: itself, a copy of
+// the code and field of the one it wraps, or an error without code or field,
+// so that no failure is left out of a report
+func asValidationError(err error) *ValidationError {
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		return &ValidationError{Err: err}
+	}
+
+	if error(vErr) == err {
+		return vErr
+	}
+
+	return &ValidationError{Code: vErr.Code, Field: vErr.Field, Err: err}
+}
+
+// ValidateAll runs every check Validate does, without stopping at the first
+// failure, and returns a report listing all of them. Unlike Validate, the
+// company is detected too; an unknown company is reported as a warning.
+// For allowing test cards to go through, simply pass true (bool) as the first argument
+func (c *Card) ValidateAll(allowTestNumbers ...bool) *Report {
+	report, _ := c.ValidateAllWith(ValidateOptions{AllowTestNumbers: len(allowTestNumbers) > 0 && allowTestNumbers[0]})
+	return report
+}
+
+// ValidateAllWith validates the card like ValidateAll, with the given options.
+// It only fails when the options are invalid.
+func (c *Card) ValidateAllWith(opts ValidateOptions) (*Report, error) {
+	return defaultValidator.withOptions(opts).ValidateAll(*c)
+}
@@ -0,0 +1,92 @@
+package creditcard
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateAll(t *testing.T) {
+	Convey("Should report every problem at once", t, func() {
+		Convey("With an expired card and a bad CVV", func() {
+			card := Card{Number: "4556974850403706", Cvv: "11", Month: "02", Year: "2001"}
+			report := card.ValidateAll()
+
+			So(report.Valid(), ShouldBeFalse)
+			So(report.Err(), ShouldNotBeNil)
+			So(len(report.Errors), ShouldEqual, 2)
+			So(report.Errors[0].Code, ShouldEqual, CodeExpired)
+			So(report.Errors[1].Code, ShouldEqual, CodeInvalidCVV)
+			So(len(report.FieldErrors(FieldCVV)), ShouldEqual, 1)
+			So(report.Company.Short, ShouldEqual, "visa")
+		})
+
+		Convey("With a short number failing Luhn", func() {
+			card := Card{Number: "1234", Cvv: "111", Month: "02", Year: "2099"}
+			report := card.ValidateAll()
+
+			So(report.Valid(), ShouldBeFalse)
+			So(len(report.FieldErrors(FieldNumber)), ShouldEqual, 1)
+			So(report.Errors[0].Code, ShouldEqual, CodeInvalidLength)
+			So(len(report.Warnings), ShouldEqual, 1)
+			So(report.Warnings[0].Code, ShouldEqual, CodeUnknownMethod)
+		})
+
+		Convey("With a number of the wrong length for its company", func() {
+			card := Card{Number: "3400000000000000009", Cvv: "1111", Month: "02", Year: "2099"}
+			report := card.ValidateAll()
+
+			So(len(report.Errors), ShouldEqual, 1)
+			So(report.Errors[0].Code, ShouldEqual, CodeWrongLengthForCompany)
+			So(report.Company.Short, ShouldEqual, "amex")
+		})
+
+		Convey("With a number failing Luhn", func() {
+			card := Card{Number: "4556974850403707", Cvv: "111", Month: "02", Year: "2099"}
+			report := card.ValidateAll()
+
+			So(len(report.Errors), ShouldEqual, 1)
+			So(report.Errors[0].Code, ShouldEqual, CodeInvalidNumber)
+		})
+	})
+
+	Convey("Test numbers", t, func() {
+		card := Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+
+		Convey("are errors by default", func() {
+			report := card.ValidateAll()
+
+			So(report.Valid(), ShouldBeFalse)
+			So(report.Errors[0].Code, ShouldEqual, CodeTestNumber)
+		})
+
+		Convey("are warnings when allowed", func() {
+			report := card.ValidateAll(true)
+
+			So(report.Valid(), ShouldBeTrue)
+			So(report.Err(), ShouldBeNil)
+			So(report.Warnings[0].Code, ShouldEqual, CodeTestNumber)
+		})
+	})
+
+	Convey("Errors should never be left out of a report", t, func() {
+		report := &Report{}
+		wrapped := fmt.Errorf("checking: %w", newValidationError(CodeInvalidCVV, FieldCVV, ErrInvalidCVV))
+		untyped := errors.New("boom")
+
+		report.addError(wrapped)
+		report.addWarning(untyped)
+		So(report.Valid(), ShouldBeFalse)
+		So(report.Errors[0].Code, ShouldEqual, CodeInvalidCVV)
+		So(report.Errors[0].Field, ShouldEqual, FieldCVV)
+		So(errors.Is(report.Err(), ErrInvalidCVV), ShouldBeTrue)
+		So(report.Warnings[0].Code, ShouldEqual, ErrorCode(""))
+		So(errors.Is(report.Warnings[0], untyped), ShouldBeTrue)
+
+		report.addError(untyped)
+		So(len(report.Errors), ShouldEqual, 2)
+		So(report.Errors[1].Error(), ShouldEqual, "boom")
+	})
+}
@@ -0,0 +1,169 @@
+package creditcard
+
+import "time"
+
+// ValidationResult is everything validation found out about a card: its
+// normalized and masked number, companies, expiration date, whether it is a
+// test card, and the problems found, see Report. A result is never modified
+// once built, and the card it was built from is left untouched, so both can
+// be shared between goroutines.
+type ValidationResult struct {
+	number    string
+	masked    string
+	companies []CompanyMatch
+	expiry    Expiry
+	hasExpiry bool
+	testCard  TestCard
+	isTest    bool
+	errors    []ValidationError
+	warnings  []ValidationError
+}
+
+// Evaluate validates the card like ValidateAll and returns everything found
+// out about it, leaving the card untouched
+func (c *Card) Evaluate() *ValidationResult {
+	result, _ := defaultValidator.Evaluate(*c)
+	return result
+}
+
+// EvaluateWith evaluates the card like Evaluate, with the given options.
+// It only fails when the options are invalid.
+func (c *Card) EvaluateWith(opts ValidateOptions) (*ValidationResult, error) {
+	return defaultValidator.withOptions(opts).Evaluate(*c)
+}
+
+// Evaluate validates the card like ValidateAll and returns everything found
+// out about it. The number is masked with the MaskPolicy of the options, or
+// MaskLastFour when none is set. It only fails when the validator's options
+// are invalid.
+func (v *Validator) Evaluate(c Card) (*ValidationResult, error) {
+	report, err := v.ValidateAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidationResult{}
+
+	for _, err := range report.Errors {
+		result.errors = append(result.errors, *err)
+	}
+
+	for _, err := range report.Warnings {
+		result.warnings = append(result.warnings, *err)
+	}
+
+	if report.TestCard != nil {
+		result.testCard, result.isTest = *report.TestCard, true
+	}
+
+	if expiry, err := v.Expiry(c); err == nil {
+		result.expiry, result.hasExpiry = expiry, true
+	}
+
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return result, nil
+	}
+	result.number = number
+
+	policy := MaskLastFour
+	if v.opts.MaskPolicy != nil {
+		policy = *v.opts.MaskPolicy
+	}
+	result.masked, _ = MaskNumber(number, policy)
+
+	result.companies, _ = v.Registry().DetectAll(number)
+
+	return result, nil
+}
+
+// Valid reports whether no errors were found
+func (r *ValidationResult) Valid() bool {
+	return len(r.errors) == 0
+}
+
+// Err returns the first error found, or nil when the card is valid
+func (r *ValidationResult) Err() error {
+	if r.Valid() {
+		return nil
+	}
+
+	err := r.errors[0]
+	return &err
+}
+
+// Errors returns the problems making the card invalid
+func (r *ValidationResult) Errors() []*ValidationError {
+	return copyErrors(r.errors)
+}
+
+// Warnings returns the informational problems found on the card
+func (r *ValidationResult) Warnings() []*ValidationError {
+	return copyErrors(r.warnings)
+}
+
+func copyErrors(errs []ValidationError) []*ValidationError {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	copies := make([]*ValidationError, len(errs))
+	for i := range errs {
+		err := errs[i]
+		copies[i] = &err
+	}
+
+	return copies
+}
+
+// Number returns the normalized card number, empty when it could not be
+// normalized
+func (r *ValidationResult) Number() string {
+	return r.number
+}
+
+// MaskedNumber returns the masked card number, empty when it could not be
+// masked
+func (r *ValidationResult) MaskedNumber() string {
+	return r.masked
+}
+
+// Company returns the primary company of the card, the one MethodValidate
+// returns, and whether it is known
+func (r *ValidationResult) Company() (Company, bool) {
+	if len(r.companies) == 0 {
+		return Company{"", ""}, false
+	}
+
+	return r.companies[0].Company, true
+}
+
+// Companies returns every company of the card, see Card.Companies
+func (r *ValidationResult) Companies() []CompanyMatch {
+	return append([]CompanyMatch(nil), r.companies...)
+}
+
+// Expiry returns the card's expiration month, and whether it is valid
+func (r *ValidationResult) Expiry() (Expiry, bool) {
+	return r.expiry, r.hasExpiry
+}
+
+// ExpiresAt returns the last instant the card is valid at, in UTC, or the
+// zero time when its expiration date is invalid
+func (r *ValidationResult) ExpiresAt() time.Time {
+	if !r.hasExpiry {
+		return time.Time{}
+	}
+
+	return r.expiry.LastValidInstant(time.UTC)
+}
+
+// TestCard returns the test card the number belongs to, and whether it is one
+func (r *ValidationResult) TestCard() (TestCard, bool) {
+	return r.testCard, r.isTest
+}
+
+// IsTestCard reports whether the number is a test card of the selected catalogs
+func (r *ValidationResult) IsTestCard() bool {
+	return r.isTest
+}
@@ -0,0 +1,99 @@
+package creditcard
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidationResult(t *testing.T) {
+	Convey("Should return everything found out about a card", t, func() {
+		card := Card{Number: "4571 0000 0000 0001", Cvv: "111", Month: "02", Year: "2099"}
+		before := card
+
+		result := card.Evaluate()
+
+		So(card, ShouldResemble, before)
+		So(result.Valid(), ShouldBeTrue)
+		So(result.Err(), ShouldBeNil)
+		So(result.Number(), ShouldEqual, "4571000000000001")
+		So(result.MaskedNumber(), ShouldEqual, "************0001")
+		So(result.IsTestCard(), ShouldBeFalse)
+
+		company, known := result.Company()
+		So(known, ShouldBeTrue)
+		So(company.Short, ShouldEqual, "visa")
+		So(len(result.Companies()), ShouldEqual, 2)
+
+		expiry, ok := result.Expiry()
+		So(ok, ShouldBeTrue)
+		So(expiry, ShouldResemble, Expiry{Month: time.February, Year: 2099})
+		So(result.ExpiresAt().Equal(time.Date(2099, 3, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)), ShouldBeTrue)
+	})
+
+	Convey("Should list every problem found", t, func() {
+		card := Card{Number: "4242424242424242", Cvv: "11", Month: "13", Year: "2099"}
+		result, err := card.EvaluateWith(ValidateOptions{MaskPolicy: &MaskFirstSixLastFour})
+		So(err, ShouldBeNil)
+
+		So(result.Valid(), ShouldBeFalse)
+		So(ErrorCodeOf(result.Err()), ShouldEqual, CodeInvalidMonth)
+		So(len(result.Errors()), ShouldEqual, 3)
+		So(result.Errors()[2].Code, ShouldEqual, CodeTestNumber)
+		So(result.Errors()[2].Number, ShouldEqual, "424242******4242")
+		So(result.MaskedNumber(), ShouldEqual, "424242******4242")
+
+		testCard, ok := result.TestCard()
+		So(ok, ShouldBeTrue)
+		So(testCard.Processor, ShouldEqual, "stripe")
+
+		_, ok = result.Expiry()
+		So(ok, ShouldBeFalse)
+		So(result.ExpiresAt().IsZero(), ShouldBeTrue)
+
+		Convey("Without being modifiable", func() {
+			result.Errors()[0].Code = CodeExpired
+			So(result.Errors()[0].Code, ShouldEqual, CodeInvalidMonth)
+		})
+
+		Convey("Numbers which cannot be normalized are left empty", func() {
+			card := Card{Number: "4242x", Cvv: "111", Month: "02", Year: "2099"}
+			result := card.Evaluate()
+
+			So(result.Number(), ShouldEqual, "")
+			So(result.MaskedNumber(), ShouldEqual, "")
+			_, known := result.Company()
+			So(known, ShouldBeFalse)
+			So(ErrorCodeOf(result.Err()), ShouldEqual, CodeInvalidCharacter)
+		})
+
+		Convey("Invalid options are an error", func() {
+			_, err := card.EvaluateWith(ValidateOptions{TestCatalogs: []string{"nope"}})
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Should let the card be shared between goroutines", t, func() {
+		card := &Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		v := NewValidator()
+
+		var wg sync.WaitGroup
+		results := make([]*ValidationResult, 20)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = v.Evaluate(*card)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			company, _ := result.Company()
+			So(company.Short, ShouldEqual, "visa")
+		}
+		So(card.Company, ShouldResemble, Company{})
+	})
+}
@@ -0,0 +1,534 @@
+package creditcard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// maxPrefixDigits is the longest IIN prefix a range can be matched against,
+// long enough for 8 digit BINs and private label ranges
+const maxPrefixDigits = len(digits{})
+
+// Checksum is the check digit algorithm a scheme's card numbers use
+type Checksum int
+
+// Supported checksum rules
+const (
+	ChecksumLuhn Checksum = iota
+	ChecksumNone
+)
+
+// IINRange is an inclusive range of issuer identification numbers, matched
+// against the first Digits digits of a card number. A non-zero MinLength or
+// MaxLength also restricts the range to numbers of that many digits.
+// Issuer, Country and Type optionally describe the cards of the range.
+// A CoBadgeOnly range only reports its scheme as a co-badged company of
+// numbers another scheme is detected for, see CoBadge: overlapping ranges
+// are not co-badges otherwise.
+type IINRange struct {
+	Digits               int
+	Low, High            int
+	MinLength, MaxLength int
+	Issuer, Country      string
+	Type                 string
+	CoBadgeOnly          bool
+}
+
+// Prefix returns a range matching a single IIN prefix, e.g. Prefix("6011")
+func Prefix(prefix string) IINRange {
+	return PrefixRange(prefix, prefix)
+}
+
+// PrefixRange returns a range matching every IIN between low and high,
+// which should have the same number of digits
+func PrefixRange(low, high string) IINRange {
+	l, _ := parseDigits(low)
+	h, _ := parseDigits(high)
+
+	return IINRange{Digits: len(low), Low: l, High: h}
+}
+
+// WithLength returns a copy of the range only matching numbers whose length
+// is between min and max, a zero bound being unbounded
+func (r IINRange) WithLength(min, max int) IINRange {
+	r.MinLength, r.MaxLength = min, max
+	return r
+}
+
+// CoBadge returns a copy of the range which never makes its scheme the
+// primary company of a number: its scheme is only listed by DetectAll, after
+// the primary company, e.g. Dankort on Visa cards
+func (r IINRange) CoBadge() IINRange {
+	r.CoBadgeOnly = true
+	return r
+}
+
+// bounds returns the range widened to prefixes of maxPrefixDigits digits,
+// so that ranges of different lengths can be compared
+func (r IINRange) bounds() (int, int) {
+	scale := 1
+	for i := r.Digits; i < maxPrefixDigits; i++ {
+		scale *= 10
+	}
+
+	return r.Low * scale, (r.High+1)*scale - 1
+}
+
+func (r IINRange) matches(ccDigits digits, ccLen int) bool {
+	if ccLen < r.Digits {
+		return false
+	}
+
+	if (r.MinLength > 0 && ccLen < r.MinLength) || (r.MaxLength > 0 && ccLen > r.MaxLength) {
+		return false
+	}
+
+	return isInBetween(ccDigits.At(r.Digits), r.Low, r.High)
+}
+
+// Scheme declares a card brand: the IIN ranges it is detected from, the
+// lengths and CVV length of its cards and its checksum rule. CVVOptional
+// schemes issue some cards without a printed security code. Schemes with
+// a lower Priority are checked first. Schemes sharing a Network, such as a
+// brand and one of its products, are never reported as co-badged; an empty
+// Network is the scheme's own short name. Groups lists how numbers are
+// printed, e.g. {4, 6, 5}, lengths without one being grouped by four.
+type Scheme struct {
+	Company     Company
+	Network     string
+	Ranges      []IINRange
+	Lengths     []int
+	Groups      [][]int
+	CVVLength   int
+	CVVOptional bool
+	Checksum    Checksum
+	Priority    int
+}
+
+// AllowsLength reports whether the scheme's cards can have n digits.
+// A scheme without declared lengths allows any.
+func (s Scheme) AllowsLength(n int) bool {
+	return len(s.Lengths) == 0 || containsInt(s.Lengths, n)
+}
+
+// clone returns a copy of the scheme not sharing any slice with it
+func (s Scheme) clone() Scheme {
+	s.Ranges = append([]IINRange(nil), s.Ranges...)
+	s.Lengths = append([]int(nil), s.Lengths...)
+
+	if s.Groups != nil {
+		groups := make([][]int, len(s.Groups))
+		for i, g := range s.Groups {
+			groups[i] = append([]int(nil), g...)
+		}
+		s.Groups = groups
+	}
+
+	return s
+}
+
+func (s Scheme) network() string {
+	if s.Network == "" {
+		return s.Company.Short
+	}
+
+	return s.Network
+}
+
+// matches reports whether the number matches one of the scheme's ranges,
+// only its co-badge only ranges being checked when coBadge is set
+func (s Scheme) matches(ccDigits digits, ccLen int, coBadge bool) bool {
+	for _, r := range s.Ranges {
+		if r.CoBadgeOnly == coBadge && r.matches(ccDigits, ccLen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Scheme) validate() error {
+	if s.Company.Short == "" {
+		return errors.New("Scheme has no short name")
+	}
+
+	if len(s.Ranges) == 0 {
+		return fmt.Errorf("Scheme %q has no IIN ranges", s.Company.Short)
+	}
+
+	for _, r := range s.Ranges {
+		if r.Digits < 1 || r.Digits > maxPrefixDigits {
+			return fmt.Errorf("Scheme %q has a range of %d digits, must be between 1 and %d", s.Company.Short, r.Digits, maxPrefixDigits)
+		}
+
+		if r.Low > r.High {
+			return fmt.Errorf("Scheme %q has an inverted range %d-%d", s.Company.Short, r.Low, r.High)
+		}
+	}
+
+	return nil
+}
+
+// Registry holds the schemes card numbers are detected against.
+// A registry can be layered on top of a parent one, see Layer.
+// It is safe for concurrent use.
+type Registry struct {
+	parent *Registry
+
+	mu      sync.RWMutex
+	schemes []Scheme
+	hidden  map[string]bool
+
+	// tables holds the schemes as they were before the last BIN table was
+	// loaded, see LoadBINTable; loadMu serializes loads
+	tables map[string]tableBase
+	loadMu sync.Mutex
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Layer returns an empty registry layered on top of r. Its schemes are checked
+// along with those of r, by priority, and override the schemes of r with the
+// same short name; removing a scheme of r only hides it from the layer. r is
+// never copied nor modified, and changes made to it are seen by the layer,
+// so that many tenants can share a table while overriding parts of it.
+func (r *Registry) Layer() *Registry {
+	return &Registry{parent: r}
+}
+
+// Parent returns the registry r is layered on top of, nil if none
+func (r *Registry) Parent() *Registry {
+	return r.parent
+}
+
+// DefaultRegistry is the registry used by Card.Method and Card.MethodValidate.
+// It starts out with the built-in schemes.
+var DefaultRegistry = newBuiltinRegistry()
+
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+	for _, s := range builtinSchemes {
+		if err := r.Register(s); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// Register adds a scheme to the registry, replacing any scheme with the same short name
+func (r *Registry) Register(s Scheme) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	s = s.clone()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schemes := make([]Scheme, 0, len(r.schemes)+1)
+	for _, existing := range r.schemes {
+		if existing.Company.Short != s.Company.Short {
+			schemes = append(schemes, existing)
+		}
+	}
+	schemes = append(schemes, s)
+
+	sort.SliceStable(schemes, func(i, j int) bool {
+		return schemes[i].Priority < schemes[j].Priority
+	})
+
+	r.schemes = schemes
+	if r.hidden[s.Company.Short] {
+		r.setHidden(s.Company.Short, false)
+	}
+	delete(r.tables, s.Company.Short)
+
+	return nil
+}
+
+// Remove deletes the scheme with the given short name, reporting whether it
+// existed. Schemes of a parent registry are hidden from the layer instead.
+func (r *Registry) Remove(short string) bool {
+	_, inParent := r.parent.Scheme(short)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := false
+	for i, s := range r.schemes {
+		if s.Company.Short == short {
+			schemes := make([]Scheme, 0, len(r.schemes)-1)
+			schemes = append(schemes, r.schemes[:i]...)
+			r.schemes = append(schemes, r.schemes[i+1:]...)
+			removed = true
+			break
+		}
+	}
+
+	if inParent && !r.hidden[short] {
+		r.setHidden(short, true)
+		removed = true
+	}
+	delete(r.tables, short)
+
+	return removed
+}
+
+// setHidden replaces the set of hidden schemes, which lookups read without
+// holding the lock. It must be called with the lock held.
+func (r *Registry) setHidden(short string, hidden bool) {
+	set := make(map[string]bool, len(r.hidden)+1)
+	for name := range r.hidden {
+		set[name] = true
+	}
+
+	if hidden {
+		set[short] = true
+	} else {
+		delete(set, short)
+	}
+
+	r.hidden = set
+}
+
+// layerView is a snapshot of the schemes of one layer of a registry
+type layerView struct {
+	schemes []Scheme
+	hidden  map[string]bool
+}
+
+func (l layerView) has(short string) bool {
+	for _, s := range l.schemes {
+		if s.Company.Short == short {
+			return true
+		}
+	}
+
+	return false
+}
+
+// views returns snapshots of the registry and of its parents, r first
+func (r *Registry) views(buf []layerView) []layerView {
+	views := buf[:0]
+	for l := r; l != nil; l = l.parent {
+		l.mu.RLock()
+		views = append(views, layerView{l.schemes, l.hidden})
+		l.mu.RUnlock()
+	}
+
+	return views
+}
+
+// visit calls fn with the schemes of the registry and of its parents in the
+// order they are checked, skipping the overridden and hidden ones, until fn
+// returns false. Layers are merged by priority, the schemes of the most
+// specific layer coming first between equal priorities.
+func (r *Registry) visit(fn func(Scheme) bool) {
+	var buf [4]layerView
+	views := r.views(buf[:])
+
+	if len(views) == 1 {
+		for _, s := range views[0].schemes {
+			if !fn(s) {
+				return
+			}
+		}
+		return
+	}
+
+	next := make([]int, len(views))
+	for {
+		best := -1
+		for i, v := range views {
+			if next[i] < len(v.schemes) && (best < 0 || v.schemes[next[i]].Priority < views[best].schemes[next[best]].Priority) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return
+		}
+
+		s := views[best].schemes[next[best]]
+		next[best]++
+
+		if !shadowed(views[:best], s.Company.Short) && !fn(s) {
+			return
+		}
+	}
+}
+
+// shadowed reports whether one of the given layers overrides or hides a scheme
+func shadowed(layers []layerView, short string) bool {
+	for _, l := range layers {
+		if l.hidden[short] || l.has(short) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scheme returns the scheme registered under the given short name
+func (r *Registry) Scheme(short string) (Scheme, bool) {
+	if r == nil {
+		return Scheme{}, false
+	}
+
+	var buf [4]layerView
+	for _, l := range r.views(buf[:]) {
+		if l.hidden[short] {
+			return Scheme{}, false
+		}
+
+		for _, s := range l.schemes {
+			if s.Company.Short == short {
+				return s, true
+			}
+		}
+	}
+
+	return Scheme{}, false
+}
+
+// Schemes returns the registered schemes in the order they are checked
+func (r *Registry) Schemes() []Scheme {
+	var schemes []Scheme
+	r.visit(func(s Scheme) bool {
+		schemes = append(schemes, s)
+		return true
+	})
+
+	return schemes
+}
+
+// Detect returns the company of the first scheme matching the card number.
+// Resolve tells issuers apart by the most specific range instead.
+func (r *Registry) Detect(number string) (Company, error) {
+	s, err := r.detect(number)
+	if err != nil {
+		return Company{"", ""}, err
+	}
+
+	return s.Company, nil
+}
+
+// detect returns the first scheme matching the number, skipping co-badge
+// only ranges
+func (r *Registry) detect(number string) (Scheme, error) {
+	ccDigits, err := newDigits(number)
+	if err != nil {
+		return Scheme{}, newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+	}
+
+	var found Scheme
+	ok := false
+	r.visit(func(s Scheme) bool {
+		if s.matches(ccDigits, len(number), false) {
+			found, ok = s, true
+		}
+		return !ok
+	})
+
+	if !ok {
+		return Scheme{}, newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+	}
+
+	return found, nil
+}
+
+// CompanyMatch is one of the companies a card number belongs to. The primary
+// company is the one Detect returns, the others are co-badged on the card.
+type CompanyMatch struct {
+	Company Company
+	Primary bool
+}
+
+// DetectAll returns every company the card number belongs to, one per network,
+// the first one being the primary company Detect returns, followed by the
+// co-badged ones in the order they are checked. Only co-badge only ranges
+// report co-badged companies, see CoBadge, so that co-badged cards such as
+// Elo/Visa or Visa/Dankort get several matches but ranges merely overlapping
+// the primary company's do not.
+func (r *Registry) DetectAll(number string) ([]CompanyMatch, error) {
+	primary, err := r.detect(number)
+	if err != nil {
+		return nil, err
+	}
+
+	ccDigits, _ := newDigits(number)
+	matches := []CompanyMatch{{Company: primary.Company, Primary: true}}
+	networks := map[string]bool{primary.network(): true}
+	r.visit(func(s Scheme) bool {
+		if networks[s.network()] || !s.matches(ccDigits, len(number), true) {
+			return true
+		}
+
+		networks[s.network()] = true
+		matches = append(matches, CompanyMatch{Company: s.Company})
+		return true
+	})
+
+	return matches, nil
+}
+
+// IssuerInfo describes the most specific IIN range a card number belongs to
+type IssuerInfo struct {
+	Company Company
+	Range   IINRange
+}
+
+// Resolve returns the most specific range matching the card number, the one
+// with the longest prefix, so that issuers sharing the same first six digits
+// can be told apart by their 8 digit BINs. Between ranges of the same length
+// the scheme checked first wins. Co-badge only ranges are skipped.
+func (r *Registry) Resolve(number string) (IssuerInfo, error) {
+	ccDigits, err := newDigits(number)
+	if err != nil {
+		return IssuerInfo{}, newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+	}
+
+	var info IssuerInfo
+	found := false
+	r.visit(func(s Scheme) bool {
+		for _, rng := range s.Ranges {
+			if !rng.CoBadgeOnly && rng.matches(ccDigits, len(number)) && (!found || rng.Digits > info.Range.Digits) {
+				info = IssuerInfo{Company: s.Company, Range: rng}
+				found = true
+			}
+		}
+		return true
+	})
+
+	if !found {
+		return IssuerInfo{}, newValidationError(CodeUnknownMethod, FieldNumber, ErrUnknownMethod)
+	}
+
+	return info, nil
+}
+
+// RegisterScheme adds or replaces a scheme in the DefaultRegistry
+func RegisterScheme(s Scheme) error {
+	return DefaultRegistry.Register(s)
+}
+
+// RemoveScheme deletes a scheme from the DefaultRegistry
+func RemoveScheme(short string) bool {
+	return DefaultRegistry.Remove(short)
+}
+
+// BuiltinSchemes returns the schemes the DefaultRegistry starts out with
+func BuiltinSchemes() []Scheme {
+	schemes := make([]Scheme, len(builtinSchemes))
+	for i, s := range builtinSchemes {
+		schemes[i] = s.clone()
+	}
+
+	return schemes
+}
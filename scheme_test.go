@@ -0,0 +1,281 @@
+package creditcard
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegistry(t *testing.T) {
+	Convey("A registry should detect schemes from their IIN ranges", t, func() {
+		registry := NewRegistry()
+		err := registry.Register(Scheme{
+			Company:  Company{"private", "Private Label"},
+			Ranges:   []IINRange{PrefixRange("990000", "990099")},
+			Lengths:  []int{16},
+			Checksum: ChecksumNone,
+			Priority: 10,
+		})
+		So(err, ShouldBeNil)
+
+		company, err := registry.Detect("9900501234567890")
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "private")
+
+		_, err = registry.Detect("9901001234567890")
+		So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+
+		Convey("Priority should decide between overlapping schemes", func() {
+			err := registry.Register(Scheme{
+				Company:  Company{"special", "Special"},
+				Ranges:   []IINRange{Prefix("990050")},
+				Priority: 5,
+			})
+			So(err, ShouldBeNil)
+
+			company, _ := registry.Detect("9900501234567890")
+			So(company.Short, ShouldEqual, "special")
+
+			company, _ = registry.Detect("9900511234567890")
+			So(company.Short, ShouldEqual, "private")
+		})
+
+		Convey("Registering a scheme with the same name should override it", func() {
+			err := registry.Register(Scheme{
+				Company: Company{"private", "Private Label"},
+				Ranges:  []IINRange{Prefix("98")},
+			})
+			So(err, ShouldBeNil)
+			So(len(registry.Schemes()), ShouldEqual, 1)
+
+			_, err = registry.Detect("9900501234567890")
+			So(err, ShouldNotBeNil)
+
+			company, _ := registry.Detect("9800501234567890")
+			So(company.Short, ShouldEqual, "private")
+		})
+
+		Convey("Removing a scheme should stop detecting it", func() {
+			So(registry.Remove("private"), ShouldBeTrue)
+			So(registry.Remove("private"), ShouldBeFalse)
+
+			_, err := registry.Detect("9900501234567890")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Length restricted ranges should only match numbers of that length", func() {
+			err := registry.Register(Scheme{
+				Company: Company{"short", "Short"},
+				Ranges:  []IINRange{Prefix("97").WithLength(14, 14)},
+			})
+			So(err, ShouldBeNil)
+
+			_, err = registry.Detect("97000000000000")
+			So(err, ShouldBeNil)
+
+			_, err = registry.Detect("970000000000000")
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Invalid schemes should be refused", t, func() {
+		registry := NewRegistry()
+
+		So(registry.Register(Scheme{Ranges: []IINRange{Prefix("4")}}), ShouldNotBeNil)
+		So(registry.Register(Scheme{Company: Company{"none", "None"}}), ShouldNotBeNil)
+		So(registry.Register(Scheme{Company: Company{"long", "Long"}, Ranges: []IINRange{Prefix("1234567890123")}}), ShouldNotBeNil)
+	})
+
+	Convey("Ranges may be longer than six digits", t, func() {
+		registry := NewRegistry()
+		So(registry.Register(Scheme{Company: Company{"wide", "Wide"}, Ranges: []IINRange{Prefix("4111")}, Priority: 2}), ShouldBeNil)
+		So(registry.Register(Scheme{Company: Company{"narrow", "Narrow"}, Ranges: []IINRange{PrefixRange("41111100", "41111149")}, Priority: 1}), ShouldBeNil)
+		So(registry.Register(Scheme{Company: Company{"private", "Private"}, Ranges: []IINRange{Prefix("99887766554")}, Priority: 3}), ShouldBeNil)
+
+		company, _ := registry.Detect("4111110012345678")
+		So(company.Short, ShouldEqual, "narrow")
+
+		company, _ = registry.Detect("4111115012345678")
+		So(company.Short, ShouldEqual, "wide")
+
+		company, _ = registry.Detect("9988776655412345")
+		So(company.Short, ShouldEqual, "private")
+
+		info, err := registry.Resolve("4111110012345678")
+		So(err, ShouldBeNil)
+		So(info.Company.Short, ShouldEqual, "narrow")
+		So(info.Range.Digits, ShouldEqual, 8)
+
+		Convey("A non-digit past the sixth character should not prevent detection", func() {
+			company, err := registry.Detect("411111x012345678")
+			So(err, ShouldBeNil)
+			So(company.Short, ShouldEqual, "wide")
+		})
+	})
+
+	Convey("Only Resolve should prefer the longest matching range", t, func() {
+		registry := newBuiltinRegistry()
+		So(registry.LoadCSV(strings.NewReader("start,end,short\n62212600,62212699,discover\n")), ShouldBeNil)
+
+		company, err := registry.Detect("6221260000000000")
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "china unionpay")
+
+		info, err := registry.Resolve("6221260000000000")
+		So(err, ShouldBeNil)
+		So(info.Company.Short, ShouldEqual, "discover")
+		So(info.Range.Digits, ShouldEqual, 8)
+	})
+
+	Convey("The built-in schemes should detect overlapping ranges by priority", t, func() {
+		numbers := map[string]string{
+			"6221260000000000":    "china unionpay",
+			"6229250000000000":    "china unionpay",
+			"6277800000000000":    "china unionpay",
+			"6500310000000000":    "discover",
+			"6504050000000000":    "discover",
+			"6550210000000000":    "discover",
+			"6390000000000000":    "instapayment",
+			"6390000000000000000": "maestro",
+			"6362970000000000":    "elo",
+			"6370950000000000":    "hipercard",
+			"6271700000000000":    "cabal",
+			"5018000000000000":    "maestro",
+			"5019000000000000":    "dankort",
+			"5090000000000000":    "elo",
+			"5000000000000000":    "aura",
+			"4011780000000000":    "elo",
+			"4026000000000000":    "visa electron",
+			"4571000000000000":    "visa",
+		}
+
+		for number, short := range numbers {
+			company, err := DefaultRegistry.Detect(number)
+			So(err, ShouldBeNil)
+			So(company.Short, ShouldEqual, short)
+		}
+	})
+
+	Convey("The default registry should be configurable at runtime", t, func() {
+		defer func() { DefaultRegistry = newBuiltinRegistry() }()
+
+		So(len(DefaultRegistry.Schemes()), ShouldEqual, len(BuiltinSchemes()))
+
+		So(RemoveScheme("visa"), ShouldBeTrue)
+		card := Card{Number: "4242424242424242"}
+		_, err := card.MethodValidate()
+		So(err, ShouldNotBeNil)
+
+		So(RegisterScheme(Scheme{Company: Company{"visa", "Visa"}, Ranges: []IINRange{Prefix("4")}}), ShouldBeNil)
+		company, err := card.MethodValidate()
+		So(err, ShouldBeNil)
+		So(company.Long, ShouldEqual, "Visa")
+	})
+
+	Convey("Schemes without a checksum should skip the Luhn check", t, func() {
+		defer func() { DefaultRegistry = newBuiltinRegistry() }()
+
+		So(RegisterScheme(Scheme{Company: Company{"private", "Private Label"}, Ranges: []IINRange{Prefix("99")}, Checksum: ChecksumNone}), ShouldBeNil)
+
+		card := Card{Number: "9900000000000001", Cvv: "111", Month: "02", Year: "2099"}
+		So(card.ValidateNumber(), ShouldBeFalse)
+		So(card.Validate(), ShouldBeNil)
+	})
+}
+
+func TestCompanies(t *testing.T) {
+	Convey("Should return every company of co-badged cards", t, func() {
+		Convey("Elo/Visa", func() {
+			card := Card{Number: "4011780000000000"}
+			matches, err := card.Companies()
+
+			So(err, ShouldBeNil)
+			So(matches, ShouldResemble, []CompanyMatch{
+				{Company{"elo", "Elo"}, true},
+				{Company{"visa", "Visa"}, false},
+			})
+		})
+
+		Convey("Visa/Dankort", func() {
+			card := Card{Number: "4571000000000000"}
+			matches, err := card.Companies()
+
+			So(err, ShouldBeNil)
+			So(matches, ShouldResemble, []CompanyMatch{
+				{Company{"visa", "Visa"}, true},
+				{Company{"dankort", "Dankort"}, false},
+			})
+
+			So(card.Method(), ShouldBeNil)
+			So(card.Company.Short, ShouldEqual, "visa")
+
+			info, err := DefaultRegistry.Resolve("4571000000000000")
+			So(err, ShouldBeNil)
+			So(info.Company.Short, ShouldEqual, "visa")
+
+			long := Card{Number: "4571000000000000003"}
+			So(long.ValidateLength(), ShouldBeNil)
+		})
+
+		Convey("Co-badge only ranges are never primary", func() {
+			registry := NewRegistry()
+			So(registry.Register(Scheme{Company: Company{"local", "Local"}, Ranges: []IINRange{Prefix("4571").CoBadge()}}), ShouldBeNil)
+
+			_, err := registry.DetectAll("4571000000000000")
+			So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+		})
+
+		Convey("But not schemes whose ranges merely overlap", func() {
+			numbers := map[string]string{
+				"5018000000000000": "maestro",
+				"5019000000000000": "dankort",
+				"6271700000000000": "cabal",
+				"6370950000000000": "hipercard",
+				"6500310000000000": "discover",
+				"6390000000000000": "instapayment",
+			}
+
+			for number, short := range numbers {
+				matches, err := DefaultRegistry.DetectAll(number)
+				So(err, ShouldBeNil)
+				So(len(matches), ShouldEqual, 1)
+				So(matches[0].Company.Short, ShouldEqual, short)
+			}
+		})
+
+		Convey("But not products of the same network", func() {
+			card := Card{Number: "4026000000000000"}
+			matches, err := card.Companies()
+
+			So(err, ShouldBeNil)
+			So(matches, ShouldResemble, []CompanyMatch{{Company{"visa electron", "Visa Electron"}, true}})
+		})
+
+		Convey("Single branded cards should only have a primary company", func() {
+			card := Card{Number: "378282246310005"}
+			matches, err := card.Companies()
+
+			So(err, ShouldBeNil)
+			So(len(matches), ShouldEqual, 1)
+			So(matches[0].Primary, ShouldBeTrue)
+		})
+
+		Convey("Unknown numbers should fail", func() {
+			card := Card{Number: "1112424242"}
+			_, err := card.Companies()
+
+			So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+		})
+	})
+
+	Convey("Should let the cardholder choose the company of a co-badged card", t, func() {
+		card := Card{Number: "4011780000000000"}
+
+		So(card.SelectCompany("visa"), ShouldBeNil)
+		So(card.Company.Short, ShouldEqual, "visa")
+
+		So(card.SelectCompany("mastercard"), ShouldNotBeNil)
+		So(card.Company.Short, ShouldEqual, "visa")
+	})
+}
@@ -0,0 +1,289 @@
+package creditcard
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// Sensitive holds a secret, such as a card number or CVV, in a byte buffer
+// that can be zeroized in place, unlike Go strings. It never prints, logs nor
+// encodes its content, compares in constant time, and can be locked in
+// memory so that it is not swapped to disk. It is safe for concurrent use.
+//
+// Revealing the secret as a string, as validation does, makes a copy which
+// cannot be zeroized; keep such copies short-lived.
+type Sensitive struct {
+	mu    sync.Mutex
+	data  []byte
+	wiped bool
+
+	// mem is the locked mapping data lives in, see Lock
+	mem []byte
+}
+
+// NewSensitive returns a Sensitive holding a copy of b. The caller should
+// zeroize b once done with it.
+func NewSensitive(b []byte) *Sensitive {
+	data := make([]byte, len(b))
+	copy(data, b)
+
+	return &Sensitive{data: data}
+}
+
+// NewSensitiveString returns a Sensitive holding a copy of s
+func NewSensitiveString(s string) *Sensitive {
+	return &Sensitive{data: []byte(s)}
+}
+
+// Lock moves the secret into its own page-aligned memory mapping, locked so
+// that it is never swapped to disk, until it is wiped. It is only supported
+// on Linux, and may fail when the process is not allowed to lock more memory
+// (see RLIMIT_MEMLOCK). Without Lock, the secret lives on the Go heap, where
+// it may be swapped to disk, and copied by the garbage collector, with no
+// guarantee. Copies returned by Bytes and Reveal are never locked. A locked
+// secret which is not wiped is released when garbage collected.
+func (s *Sensitive) Lock() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wiped {
+		return newValidationError(CodeWiped, FieldNumber, ErrWiped)
+	}
+
+	return s.lock()
+}
+
+// lock moves the secret into a locked mapping. It must be called with the
+// lock held.
+func (s *Sensitive) lock() error {
+	if s.mem != nil || len(s.data) == 0 {
+		return nil
+	}
+
+	mem, err := lockedAlloc(len(s.data))
+	if err != nil {
+		return err
+	}
+
+	copy(mem, s.data)
+	zeroBytes(s.data)
+	s.data, s.mem = mem[:len(s.data)], mem
+	runtime.SetFinalizer(s, (*Sensitive).Wipe)
+
+	return nil
+}
+
+// Bytes returns a copy of the secret, failing with ErrWiped once it is wiped
+func (s *Sensitive) Bytes() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wiped {
+		return nil, newValidationError(CodeWiped, FieldNumber, ErrWiped)
+	}
+
+	b := make([]byte, len(s.data))
+	copy(b, s.data)
+	return b, nil
+}
+
+// Reveal returns the secret as a string, failing with ErrWiped once it is wiped
+func (s *Sensitive) Reveal() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wiped {
+		return "", newValidationError(CodeWiped, FieldNumber, ErrWiped)
+	}
+
+	return string(s.data), nil
+}
+
+// reveal returns the secret as a string, empty when s is nil or wiped
+func (s *Sensitive) reveal() string {
+	if s == nil {
+		return ""
+	}
+
+	secret, _ := s.Reveal()
+	return secret
+}
+
+// replace zeroizes the secret and holds a copy of b instead
+func (s *Sensitive) replace(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locked := s.mem != nil
+	s.zeroize()
+	s.data = append([]byte(nil), b...)
+	if locked {
+		s.lock()
+	}
+}
+
+// Len returns the length of the secret, zero once it is wiped
+func (s *Sensitive) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.data)
+}
+
+// Wipe overwrites the secret with zeros and unlocks it from memory. Any later
+// attempt to use it fails with ErrWiped. Wiping a nil Sensitive does nothing.
+func (s *Sensitive) Wipe() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.zeroize()
+	s.data = nil
+	s.wiped = true
+}
+
+// zeroize overwrites the secret with zeros and releases its locked mapping,
+// leaving data to be replaced. It must be called with the lock held.
+func (s *Sensitive) zeroize() {
+	zeroBytes(s.data)
+
+	if s.mem != nil {
+		zeroBytes(s.mem)
+		lockedFree(s.mem)
+		s.data, s.mem = nil, nil
+		runtime.SetFinalizer(s, nil)
+	}
+}
+
+// Wiped reports whether the secret was wiped. A nil Sensitive is not.
+func (s *Sensitive) Wiped() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.wiped
+}
+
+// Equal reports whether both secrets are the same, in constant time for
+// secrets of the same length. Wiped secrets are never equal.
+func (s *Sensitive) Equal(other *Sensitive) bool {
+	b, err := other.Bytes()
+	if err != nil {
+		return false
+	}
+	defer zeroBytes(b)
+
+	return s.EqualBytes(b)
+}
+
+// EqualBytes reports whether the secret is b, in constant time for a b of
+// the secret's length. A wiped secret is never equal.
+func (s *Sensitive) EqualBytes(b []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.wiped && subtle.ConstantTimeCompare(s.data, b) == 1
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// String never returns the secret, only whether it is wiped
+func (s *Sensitive) String() string {
+	if s.Wiped() {
+		return "[WIPED]"
+	}
+
+	return redacted
+}
+
+// GoString prints the secret like String
+func (s *Sensitive) GoString() string {
+	return s.String()
+}
+
+// Format prints the secret like String, whatever the verb
+func (s *Sensitive) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, s.String())
+}
+
+// LogValue logs the secret like String
+func (s *Sensitive) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalJSON encodes the secret like String
+func (s *Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Secure moves the card's number and CVV into Sensitive values, which take
+// precedence over Number and Cvv, and clears Number and Cvv. Values already
+// secured are kept.
+func (c *Card) Secure() {
+	if c.SecureNumber == nil {
+		c.SecureNumber = NewSensitiveString(c.Number)
+	}
+
+	if c.SecureCvv == nil {
+		c.SecureCvv = NewSensitiveString(c.Cvv)
+	}
+
+	c.Number, c.Cvv = "", ""
+}
+
+// Wiped reports whether the card was wiped, by Wipe or by wiping its
+// SecureNumber. Wiped cards are rejected by every validation method with
+// ErrWiped.
+func (c *Card) Wiped() bool {
+	return c.wiped || c.SecureNumber.Wiped()
+}
+
+// number returns the card's number, from SecureNumber when set
+func (c *Card) number() string {
+	if c.SecureNumber != nil {
+		return c.SecureNumber.reveal()
+	}
+
+	return c.Number
+}
+
+// cvv returns the card's CVV, from SecureCvv when set
+func (c *Card) cvv() string {
+	if c.SecureCvv != nil {
+		return c.SecureCvv.reveal()
+	}
+
+	return c.Cvv
+}
+
+// checkNumber fails with ErrWiped when the card's number was wiped
+func (c *Card) checkNumber() error {
+	if c.Wiped() {
+		return newValidationError(CodeWiped, FieldNumber, ErrWiped)
+	}
+
+	return nil
+}
+
+// checkCVV fails with ErrWiped when the card's CVV was wiped
+func (c *Card) checkCVV() error {
+	if c.wiped || c.SecureCvv.Wiped() {
+		return newValidationError(CodeWiped, FieldCVV, ErrWiped)
+	}
+
+	return nil
+}
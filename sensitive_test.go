@@ -0,0 +1,168 @@
+package creditcard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSensitive(t *testing.T) {
+	Convey("Should hold a secret that can be zeroized", t, func() {
+		source := []byte("4556974850403706")
+		secret := NewSensitive(source)
+		zeroBytes(source)
+
+		revealed, err := secret.Reveal()
+		So(err, ShouldBeNil)
+		So(revealed, ShouldEqual, "4556974850403706")
+		So(secret.Len(), ShouldEqual, 16)
+		So(secret.Wiped(), ShouldBeFalse)
+
+		data := secret.data
+		secret.Wipe()
+
+		So(secret.Wiped(), ShouldBeTrue)
+		So(data, ShouldResemble, make([]byte, 16))
+		So(secret.Len(), ShouldEqual, 0)
+
+		_, err = secret.Reveal()
+		So(errors.Is(err, ErrWiped), ShouldBeTrue)
+		_, err = secret.Bytes()
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		So(secret.Lock(), ShouldNotBeNil)
+
+		var none *Sensitive
+		none.Wipe()
+		So(none.Wiped(), ShouldBeFalse)
+	})
+
+	Convey("Should compare secrets in constant time", t, func() {
+		a := NewSensitiveString("123")
+		b := NewSensitiveString("123")
+
+		So(a.Equal(b), ShouldBeTrue)
+		So(a.EqualBytes([]byte("124")), ShouldBeFalse)
+		So(a.EqualBytes([]byte("1234")), ShouldBeFalse)
+
+		b.Wipe()
+		So(a.Equal(b), ShouldBeFalse)
+		So(b.Equal(a), ShouldBeFalse)
+	})
+
+	Convey("Should never print nor encode the secret", t, func() {
+		secret := NewSensitiveString("4556974850403706")
+
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+			So(fmt.Sprintf(format, secret), ShouldEqual, "[REDACTED]")
+		}
+
+		data, err := json.Marshal(secret)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `"[REDACTED]"`)
+
+		secret.Wipe()
+		So(secret.String(), ShouldEqual, "[WIPED]")
+	})
+
+	Convey("Should lock every secret in its own pages on Linux", t, func() {
+		a := NewSensitiveString("4556974850403706")
+		b := NewSensitiveString("4556974850403714")
+		errA, errB := a.Lock(), b.Lock()
+
+		if runtime.GOOS != "linux" || errA != nil || errB != nil {
+			So(a.mem == nil || b.mem == nil, ShouldBeTrue)
+			return
+		}
+
+		page := uintptr(os.Getpagesize())
+		So(uintptr(unsafe.Pointer(&a.mem[0]))%page, ShouldEqual, 0)
+		So(uintptr(unsafe.Pointer(&b.mem[0]))%page, ShouldEqual, 0)
+		So(&a.mem[0], ShouldNotEqual, &b.mem[0])
+		So(a.Lock(), ShouldBeNil)
+
+		a.Wipe()
+		So(a.mem, ShouldBeNil)
+
+		revealed, err := b.Reveal()
+		So(err, ShouldBeNil)
+		So(revealed, ShouldEqual, "4556974850403714")
+		So(b.mem, ShouldNotBeNil)
+
+		b.replace([]byte("4111111111111111"))
+		So(b.mem, ShouldNotBeNil)
+		So(b.EqualBytes([]byte("4111111111111111")), ShouldBeTrue)
+
+		b.Wipe()
+		So(b.mem, ShouldBeNil)
+	})
+}
+
+func TestWipedCard(t *testing.T) {
+	Convey("Every validation method should reject a wiped card", t, func() {
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		So(card.Validate(), ShouldBeNil)
+
+		card.Wipe()
+		So(card.Wiped(), ShouldBeTrue)
+
+		So(errors.Is(card.Validate(), ErrWiped), ShouldBeTrue)
+		So(ErrorCodeOf(card.ValidateExpiration()), ShouldEqual, CodeWiped)
+		So(ErrorCodeOf(card.ValidateCVV()), ShouldEqual, CodeWiped)
+		So(ErrorCodeOf(card.ValidateLength()), ShouldEqual, CodeWiped)
+		So(card.ValidateNumber(), ShouldBeFalse)
+
+		_, err := card.MethodValidate()
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		_, err = card.LastFour()
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		_, err = card.Mask(MaskLastFour)
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+		So(ErrorCodeOf(card.Normalize()), ShouldEqual, CodeWiped)
+
+		report := card.ValidateAll()
+		So(len(report.Errors), ShouldEqual, 1)
+		So(report.Errors[0].Code, ShouldEqual, CodeWiped)
+
+		So(card.String(), ShouldEqual, "{Number:[WIPED] Cvv:[WIPED] Month: Year: Company:}")
+	})
+
+	Convey("Cards can be backed by sensitive values", t, func() {
+		card := Card{Number: "4556 9748 5040 3706", Cvv: "111", Month: "02", Year: "2099"}
+		card.Secure()
+
+		So(card.Number, ShouldEqual, "")
+		So(card.Cvv, ShouldEqual, "")
+		So(card.Validate(), ShouldBeNil)
+		So(card.String(), ShouldEqual, "{Number:************3706 Cvv:[REDACTED] Month:02 Year:2099 Company:}")
+
+		So(card.Normalize(), ShouldBeNil)
+		So(card.SecureNumber.EqualBytes([]byte("4556974850403706")), ShouldBeTrue)
+
+		full := card.Unredacted()
+		So(full.Number, ShouldEqual, "4556974850403706")
+		So(full.Cvv, ShouldEqual, "111")
+
+		Convey("Wiping the CVV only rejects the CVV", func() {
+			card.SecureCvv.Wipe()
+
+			So(ErrorCodeOf(card.ValidateCVV()), ShouldEqual, CodeWiped)
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeWiped)
+			_, err := card.MethodValidate()
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Wiping the card zeroizes its sensitive values", func() {
+			number := card.SecureNumber
+			card.Wipe()
+
+			So(number.Wiped(), ShouldBeTrue)
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeWiped)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package creditcard
+
+import "sync"
+
+// Derive returns a new validator with the configuration of v, changed by the
+// given options
+func (v *Validator) Derive(options ...Option) *Validator {
+	w := *v
+	w.opts.TestCatalogs = append([]string(nil), v.opts.TestCatalogs...)
+	w.opts.AcceptedBrands = append([]string(nil), v.opts.AcceptedBrands...)
+
+	for _, option := range options {
+		option(&w)
+	}
+
+	return &w
+}
+
+// Tenants hands out a validator per tenant, for processes serving several
+// merchants. Each tenant validator starts from the configuration of the base
+// validator, with its own registry layered on top of the base registry, see
+// Registry.Layer, so that tenants can override BIN ranges without copying
+// the shared table. It is safe for concurrent use.
+type Tenants struct {
+	base *Validator
+
+	mu      sync.RWMutex
+	tenants map[string]*Validator
+}
+
+// NewTenants returns tenants whose validators derive from base, or from a
+// validator with the default configuration when nil
+func NewTenants(base *Validator) *Tenants {
+	if base == nil {
+		base = NewValidator()
+	}
+
+	return &Tenants{base: base, tenants: map[string]*Validator{}}
+}
+
+// Set configures the validator of a tenant with the given options, e.g. its
+// acceptance policy, replacing any previous configuration, and returns it.
+// Schemes registered in the returned validator's Registry only apply to the
+// tenant.
+func (t *Tenants) Set(id string, options ...Option) *Validator {
+	layer := WithRegistry(t.base.Registry().Layer())
+	v := t.base.Derive(append([]Option{layer}, options...)...)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tenants[id] = v
+	return v
+}
+
+// Get returns the validator of a tenant, or the base validator for tenants
+// which were never set
+func (t *Tenants) Get(id string) *Validator {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if v, ok := t.tenants[id]; ok {
+		return v
+	}
+
+	return t.base
+}
+
+// Delete forgets a tenant, reporting whether it was set
+func (t *Tenants) Delete(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.tenants[id]
+	delete(t.tenants, id)
+	return ok
+}
@@ -0,0 +1,152 @@
+package creditcard
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLayeredRegistry(t *testing.T) {
+	Convey("A layer should override its parent without changing it", t, func() {
+		base := NewRegistry()
+		So(base.Register(Scheme{Company: Company{"visa", "Visa"}, Ranges: []IINRange{Prefix("4")}, Priority: 10}), ShouldBeNil)
+		So(base.Register(Scheme{Company: Company{"amex", "American Express"}, Ranges: []IINRange{Prefix("34"), Prefix("37")}, Priority: 10}), ShouldBeNil)
+
+		layer := base.Layer()
+		So(layer.Parent(), ShouldEqual, base)
+		So(len(layer.Schemes()), ShouldEqual, 2)
+
+		company, err := layer.Detect("4111111111111111")
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "visa")
+
+		Convey("Schemes of the layer are checked by priority with the parent's", func() {
+			So(layer.Register(Scheme{Company: Company{"local", "Local"}, Ranges: []IINRange{Prefix("411111")}, Priority: 5}), ShouldBeNil)
+
+			company, _ := layer.Detect("4111111111111111")
+			So(company.Short, ShouldEqual, "local")
+
+			company, _ = base.Detect("4111111111111111")
+			So(company.Short, ShouldEqual, "visa")
+
+			matches, _ := layer.DetectAll("4111111111111111")
+			So(len(matches), ShouldEqual, 1)
+		})
+
+		Convey("Schemes with the same name override the parent's", func() {
+			So(layer.Register(Scheme{Company: Company{"visa", "Visa"}, Ranges: []IINRange{Prefix("41")}, Priority: 10}), ShouldBeNil)
+
+			_, err := layer.Detect("4211111111111111")
+			So(err, ShouldNotBeNil)
+
+			s, _ := layer.Scheme("visa")
+			So(s.Ranges[0].Low, ShouldEqual, 41)
+			So(len(layer.Schemes()), ShouldEqual, 2)
+
+			s, _ = base.Scheme("visa")
+			So(s.Ranges[0].Low, ShouldEqual, 4)
+		})
+
+		Convey("Removing a parent's scheme hides it from the layer only", func() {
+			So(layer.Remove("amex"), ShouldBeTrue)
+			So(layer.Remove("amex"), ShouldBeFalse)
+
+			_, err := layer.Detect("371449635398431")
+			So(err, ShouldNotBeNil)
+			_, found := layer.Scheme("amex")
+			So(found, ShouldBeFalse)
+
+			_, err = base.Detect("371449635398431")
+			So(err, ShouldBeNil)
+
+			So(layer.Register(Scheme{Company: Company{"amex", "American Express"}, Ranges: []IINRange{Prefix("37")}}), ShouldBeNil)
+			_, err = layer.Detect("371449635398431")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Changes to the parent are seen by the layer", func() {
+			So(base.Register(Scheme{Company: Company{"discover", "Discover"}, Ranges: []IINRange{Prefix("6011")}}), ShouldBeNil)
+
+			company, _ := layer.Detect("6011111111111117")
+			So(company.Short, ShouldEqual, "discover")
+		})
+
+		Convey("BIN tables can be loaded into a layer", func() {
+			So(layer.LoadCSV(strings.NewReader("start,end,short\n401200,401299,tenantbank\n")), ShouldBeNil)
+
+			company, _ := layer.Detect("4012888888881881")
+			So(company.Short, ShouldEqual, "tenantbank")
+			company, _ = base.Detect("4012888888881881")
+			So(company.Short, ShouldEqual, "visa")
+		})
+	})
+}
+
+func TestTenants(t *testing.T) {
+	Convey("Tenants should get isolated validators", t, func() {
+		tenants := NewTenants(NewValidator(WithTestCards(false, "stripe")))
+
+		br := tenants.Set("br", WithPolicy(AcceptancePolicy{AcceptedBrands: []string{"elo", "hipercard", "cabal", "naranja"}}))
+		us := tenants.Set("us", WithAcceptedBrands("visa", "mastercard"))
+
+		So(tenants.Get("br"), ShouldEqual, br)
+		So(tenants.Get("unknown"), ShouldNotEqual, br)
+
+		visa := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		So(us.Validate(visa), ShouldBeNil)
+		So(ErrorCodeOf(br.Validate(visa)), ShouldEqual, CodeBrandNotAccepted)
+		So(tenants.Get("unknown").Validate(visa), ShouldBeNil)
+
+		Convey("Scheme overrides only apply to their tenant", func() {
+			So(br.Registry().Register(Scheme{Company: Company{"elo", "Elo"}, Ranges: []IINRange{Prefix("455697")}, Priority: -1}), ShouldBeNil)
+
+			So(br.Validate(visa), ShouldBeNil)
+			company, _ := br.MethodValidate(visa)
+			So(company.Short, ShouldEqual, "elo")
+
+			company, _ = us.MethodValidate(visa)
+			So(company.Short, ShouldEqual, "visa")
+			company, _ = visa.MethodValidate()
+			So(company.Short, ShouldEqual, "visa")
+		})
+
+		Convey("The base configuration is inherited", func() {
+			card := Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+			So(ErrorCodeOf(us.Validate(card)), ShouldEqual, CodeTestNumber)
+		})
+
+		Convey("Tenants can be deleted", func() {
+			So(tenants.Delete("us"), ShouldBeTrue)
+			So(tenants.Delete("us"), ShouldBeFalse)
+			So(tenants.Get("us"), ShouldNotEqual, us)
+		})
+	})
+
+	Convey("Tenants should be safe for concurrent use", t, func() {
+		tenants := NewTenants(nil)
+		visa := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 200)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id := fmt.Sprintf("tenant-%d", i%20)
+				if i%2 == 0 {
+					v := tenants.Set(id)
+					v.Registry().Register(Scheme{Company: Company{id, id}, Ranges: []IINRange{Prefix("99")}})
+				}
+				errs[i] = tenants.Get(id).Validate(visa)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			So(err, ShouldBeNil)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package creditcard
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TestCard is a test number published by a payment processor, with the
+// scenario it triggers in the processor's sandbox
+type TestCard struct {
+	Number    string
+	Processor string
+	Scenario  string
+}
+
+// TestCatalog is a set of test cards, usually those of one payment processor
+type TestCatalog interface {
+	Name() string
+	Lookup(number string) (TestCard, bool)
+}
+
+// StaticCatalog is a TestCatalog backed by a fixed list of cards
+type StaticCatalog struct {
+	name  string
+	cards map[string]TestCard
+}
+
+// NewTestCatalog returns a catalog of the given cards. Their numbers are
+// normalized and cards without a processor get the catalog's name.
+func NewTestCatalog(name string, cards ...TestCard) *StaticCatalog {
+	catalog := &StaticCatalog{name: name, cards: make(map[string]TestCard, len(cards))}
+	for _, card := range cards {
+		if number, err := NormalizeNumber(card.Number); err == nil {
+			card.Number = number
+		}
+		if card.Processor == "" {
+			card.Processor = name
+		}
+		catalog.cards[card.Number] = card
+	}
+
+	return catalog
+}
+
+// Name returns the name of the catalog
+func (c *StaticCatalog) Name() string {
+	return c.name
+}
+
+// Lookup returns the test card with the given normalized number
+func (c *StaticCatalog) Lookup(number string) (TestCard, bool) {
+	card, ok := c.cards[number]
+	return card, ok
+}
+
+// Cards returns the cards of the catalog, sorted by number
+func (c *StaticCatalog) Cards() []TestCard {
+	cards := make([]TestCard, 0, len(c.cards))
+	for _, card := range c.cards {
+		cards = append(cards, card)
+	}
+
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Number < cards[j].Number
+	})
+
+	return cards
+}
+
+var (
+	testCatalogsMu      sync.RWMutex
+	testCatalogs        = map[string]TestCatalog{}
+	defaultTestCatalogs = []string{"stripe"}
+)
+
+func init() {
+	for _, catalog := range builtinTestCatalogs {
+		RegisterTestCatalog(catalog)
+	}
+}
+
+// RegisterTestCatalog makes a catalog selectable by its name, replacing any
+// catalog with the same name
+func RegisterTestCatalog(catalog TestCatalog) {
+	testCatalogsMu.Lock()
+	defer testCatalogsMu.Unlock()
+
+	testCatalogs[catalog.Name()] = catalog
+}
+
+// TestCatalogNames returns the names of the registered catalogs, sorted
+func TestCatalogNames() []string {
+	testCatalogsMu.RLock()
+	defer testCatalogsMu.RUnlock()
+
+	names := make([]string, 0, len(testCatalogs))
+	for name := range testCatalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DefaultTestCatalogs returns the catalogs used when none are selected, by
+// default the Stripe test cards Validate has always recognized
+func DefaultTestCatalogs() []string {
+	testCatalogsMu.RLock()
+	defer testCatalogsMu.RUnlock()
+
+	return append([]string(nil), defaultTestCatalogs...)
+}
+
+// SetDefaultTestCatalogs changes the catalogs used when none are selected,
+// which must be registered. Without any, no number is a test card by default.
+func SetDefaultTestCatalogs(names ...string) error {
+	testCatalogsMu.Lock()
+	defer testCatalogsMu.Unlock()
+
+	for _, name := range names {
+		if _, ok := testCatalogs[name]; !ok {
+			return fmt.Errorf("Unknown test card catalog %q", name)
+		}
+	}
+
+	defaultTestCatalogs = append([]string(nil), names...)
+	return nil
+}
+
+// checkTestCatalogs makes sure every name is a registered catalog
+func checkTestCatalogs(names []string) error {
+	testCatalogsMu.RLock()
+	defer testCatalogsMu.RUnlock()
+
+	for _, name := range names {
+		if _, ok := testCatalogs[name]; !ok {
+			return fmt.Errorf("Unknown test card catalog %q", name)
+		}
+	}
+
+	return nil
+}
+
+// FindTestCard looks the number up in the named catalogs, or in the
+// DefaultTestCatalogs when none are given. Unknown catalogs are skipped.
+func FindTestCard(number string, catalogs ...string) (TestCard, bool) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return TestCard{}, false
+	}
+
+	testCatalogsMu.RLock()
+	defer testCatalogsMu.RUnlock()
+
+	if len(catalogs) == 0 {
+		catalogs = defaultTestCatalogs
+	}
+
+	for _, name := range catalogs {
+		if catalog, ok := testCatalogs[name]; ok {
+			if card, found := catalog.Lookup(number); found {
+				return card, true
+			}
+		}
+	}
+
+	return TestCard{}, false
+}
+
+// isKnownTestNumber reports whether the number is in any registered catalog
+func isKnownTestNumber(number string) bool {
+	_, found := FindTestCard(number, TestCatalogNames()...)
+	return found
+}
+
+// TestCard returns the test card the card's number belongs to in the named
+// catalogs, or in the DefaultTestCatalogs when none are given
+func (c *Card) TestCard(catalogs ...string) (TestCard, bool) {
+	if c.Wiped() {
+		return TestCard{}, false
+	}
+
+	return FindTestCard(c.number(), catalogs...)
+}
+
+// TestCardError is the cause of a ValidationError rejecting a test number,
+// telling which catalog entry matched
+type TestCardError struct {
+	Card TestCard
+}
+
+func (e *TestCardError) Error() string {
+	return ErrTestNumber.Error()
+}
+
+// Unwrap returns ErrTestNumber
+func (e *TestCardError) Unwrap() error {
+	return ErrTestNumber
+}
+
+func newTestCardError(card TestCard) *ValidationError {
+	return newValidationError(CodeTestNumber, FieldNumber, &TestCardError{Card: card})
+}
+
+var builtinTestCatalogs = []TestCatalog{
+	// https://stripe.com/docs/testing
+	NewTestCatalog("stripe",
+		TestCard{Number: "4242424242424242", Scenario: "Visa, succeeds"},
+		TestCard{Number: "4012888888881881", Scenario: "Visa, succeeds"},
+		TestCard{Number: "4000056655665556", Scenario: "Visa debit, succeeds"},
+		TestCard{Number: "5555555555554444", Scenario: "Mastercard, succeeds"},
+		TestCard{Number: "5200828282828210", Scenario: "Mastercard debit, succeeds"},
+		TestCard{Number: "5105105105105100", Scenario: "Mastercard prepaid, succeeds"},
+		TestCard{Number: "378282246310005", Scenario: "American Express, succeeds"},
+		TestCard{Number: "371449635398431", Scenario: "American Express, succeeds"},
+		TestCard{Number: "6011111111111117", Scenario: "Discover, succeeds"},
+		TestCard{Number: "6011000990139424", Scenario: "Discover, succeeds"},
+		TestCard{Number: "30569309025904", Scenario: "Diners Club, succeeds"},
+		TestCard{Number: "38520000023237", Scenario: "Diners Club, succeeds"},
+		TestCard{Number: "3530111333300000", Scenario: "JCB, succeeds"},
+		TestCard{Number: "3566002020360505", Scenario: "JCB, succeeds"},
+		TestCard{Number: "4111111111111111", Scenario: "Visa, succeeds"},
+		TestCard{Number: "4916909992637469", Scenario: "Visa, succeeds"},
+		TestCard{Number: "4000111111111115", Scenario: "Visa, succeeds"},
+		TestCard{Number: "2223000048400011", Scenario: "Mastercard 2-series, succeeds"},
+		TestCard{Number: "6035227716427021", Scenario: "Cabal, succeeds"},
+	),
+	// https://docs.adyen.com/development-resources/testing/test-card-numbers
+	NewTestCatalog("adyen",
+		TestCard{Number: "4111111145551142", Scenario: "Visa, authorised"},
+		TestCard{Number: "4400000000000008", Scenario: "Visa debit, authorised"},
+		TestCard{Number: "5555341244441115", Scenario: "Mastercard, authorised"},
+		TestCard{Number: "2222400070000005", Scenario: "Mastercard 2-series, authorised"},
+		TestCard{Number: "370000000000002", Scenario: "American Express, authorised"},
+		TestCard{Number: "6011601160116611", Scenario: "Discover, authorised"},
+		TestCard{Number: "36006666333344", Scenario: "Diners Club, authorised"},
+		TestCard{Number: "3569990010095841", Scenario: "JCB, authorised"},
+		TestCard{Number: "6703444444444449", Scenario: "Bancontact, authorised"},
+	),
+	// https://developer.paypal.com/braintree/docs/reference/general/testing
+	NewTestCatalog("braintree",
+		TestCard{Number: "4111111111111111", Scenario: "Visa, settles"},
+		TestCard{Number: "4005519200000004", Scenario: "Visa, settles"},
+		TestCard{Number: "4009348888881881", Scenario: "Visa, settles"},
+		TestCard{Number: "4012000033330026", Scenario: "Visa, settles"},
+		TestCard{Number: "4012000077777777", Scenario: "Visa, settles"},
+		TestCard{Number: "4012888888881881", Scenario: "Visa, settles"},
+		TestCard{Number: "4217651111111119", Scenario: "Visa, settles"},
+		TestCard{Number: "4500600000000061", Scenario: "Visa, settles"},
+		TestCard{Number: "5555555555554444", Scenario: "Mastercard, settles"},
+		TestCard{Number: "2223000048400011", Scenario: "Mastercard 2-series, settles"},
+		TestCard{Number: "378282246310005", Scenario: "American Express, settles"},
+		TestCard{Number: "371449635398431", Scenario: "American Express, settles"},
+		TestCard{Number: "6011111111111117", Scenario: "Discover, settles"},
+		TestCard{Number: "3530111333300000", Scenario: "JCB, settles"},
+		TestCard{Number: "6304000000000000", Scenario: "Maestro, settles"},
+		TestCard{Number: "36259600000004", Scenario: "Diners Club, settles"},
+		TestCard{Number: "4000111111111115", Scenario: "Visa, processor declined"},
+		TestCard{Number: "5105105105105100", Scenario: "Mastercard, processor declined"},
+	),
+	// https://www.checkout.com/docs/testing/test-card-numbers
+	NewTestCatalog("checkout",
+		TestCard{Number: "4242424242424242", Scenario: "Visa, approved"},
+		TestCard{Number: "4543474002249996", Scenario: "Visa, approved"},
+		TestCard{Number: "5436031030606378", Scenario: "Mastercard, approved"},
+		TestCard{Number: "5199992312641465", Scenario: "Mastercard, approved"},
+		TestCard{Number: "345678901234564", Scenario: "American Express, approved"},
+		TestCard{Number: "378282246310005", Scenario: "American Express, approved"},
+	),
+}
@@ -0,0 +1,108 @@
+package creditcard
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTestCatalogs(t *testing.T) {
+	Convey("Should find test cards in the selected catalogs", t, func() {
+		Convey("Stripe is the default catalog", func() {
+			card, found := FindTestCard("4242 4242 4242 4242")
+
+			So(found, ShouldBeTrue)
+			So(card.Processor, ShouldEqual, "stripe")
+			So(card.Scenario, ShouldEqual, "Visa, succeeds")
+
+			_, found = FindTestCard("4111111145551142")
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("Other catalogs are looked up once selected", func() {
+			card, found := FindTestCard("4111111145551142", "stripe", "adyen")
+
+			So(found, ShouldBeTrue)
+			So(card.Processor, ShouldEqual, "adyen")
+		})
+
+		Convey("The default catalogs can be changed", func() {
+			defaults := DefaultTestCatalogs()
+			defaults[0] = "adyen"
+			So(DefaultTestCatalogs(), ShouldResemble, []string{"stripe"})
+
+			So(SetDefaultTestCatalogs("nope"), ShouldNotBeNil)
+			So(SetDefaultTestCatalogs("adyen"), ShouldBeNil)
+			defer SetDefaultTestCatalogs("stripe")
+
+			_, found := FindTestCard("4111111145551142")
+			So(found, ShouldBeTrue)
+
+			card := Card{Number: "4111111145551142", Cvv: "111", Month: "02", Year: "2099"}
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeTestNumber)
+		})
+
+		Convey("The registered catalogs are listed", func() {
+			So(TestCatalogNames(), ShouldResemble, []string{"adyen", "braintree", "checkout", "stripe"})
+		})
+	})
+
+	Convey("Should validate against the selected catalogs", t, func() {
+		card := Card{Number: "4111111145551142", Cvv: "111", Month: "02", Year: "2099"}
+
+		So(card.Validate(), ShouldBeNil)
+
+		err := card.ValidateWith(ValidateOptions{TestCatalogs: []string{"adyen"}})
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, ErrTestNumber), ShouldBeTrue)
+
+		var tcErr *TestCardError
+		So(errors.As(err, &tcErr), ShouldBeTrue)
+		So(tcErr.Card.Processor, ShouldEqual, "adyen")
+		So(tcErr.Card.Scenario, ShouldEqual, "Visa, authorised")
+
+		err = card.ValidateWith(ValidateOptions{AllowTestNumbers: true, TestCatalogs: []string{"adyen"}})
+		So(err, ShouldBeNil)
+
+		Convey("Unknown catalogs are an error", func() {
+			err := card.ValidateWith(ValidateOptions{TestCatalogs: []string{"nope"}})
+			So(err, ShouldNotBeNil)
+			So(ErrorCodeOf(err), ShouldEqual, ErrorCode(""))
+
+			report, err := card.ValidateAllWith(ValidateOptions{TestCatalogs: []string{"nope"}})
+			So(err, ShouldNotBeNil)
+			So(report, ShouldBeNil)
+		})
+
+		Convey("The report tells which test card matched", func() {
+			report, err := card.ValidateAllWith(ValidateOptions{AllowTestNumbers: true, TestCatalogs: []string{"adyen"}})
+
+			So(err, ShouldBeNil)
+			So(report.Valid(), ShouldBeTrue)
+			So(report.TestCard, ShouldNotBeNil)
+			So(report.TestCard.Processor, ShouldEqual, "adyen")
+			So(report.Warnings[0].Code, ShouldEqual, CodeTestNumber)
+		})
+	})
+
+	Convey("Should accept custom catalogs", t, func() {
+		RegisterTestCatalog(NewTestCatalog("acme",
+			TestCard{Number: "4000 0000 0000 0002", Scenario: "declined"},
+		))
+		defer func() {
+			testCatalogsMu.Lock()
+			delete(testCatalogs, "acme")
+			testCatalogsMu.Unlock()
+		}()
+
+		catalog := NewTestCatalog("acme", TestCard{Number: "4000000000000002", Processor: "acme bank"})
+		So(catalog.Cards()[0].Processor, ShouldEqual, "acme bank")
+
+		card := Card{Number: "4000000000000002", Cvv: "111", Month: "02", Year: "2099"}
+		So(card.Validate(), ShouldBeNil)
+
+		err := card.ValidateWith(ValidateOptions{TestCatalogs: []string{"acme"}})
+		So(ErrorCodeOf(err), ShouldEqual, CodeTestNumber)
+	})
+}
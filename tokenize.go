@@ -0,0 +1,221 @@
+package creditcard
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+// FPE is a format-preserving encryption algorithm of NIST SP 800-38G
+type FPE int
+
+// FPE algorithms a Tokenizer can use
+const (
+	FF1 FPE = iota
+	FF31
+)
+
+// String returns the name of the algorithm
+func (f FPE) String() string {
+	switch f {
+	case FF1:
+		return "FF1"
+	case FF31:
+		return "FF3-1"
+	default:
+		return fmt.Sprintf("FPE(%d)", int(f))
+	}
+}
+
+// minTokenDigits is the minimum number of encrypted digits, for the domain
+// to hold at least one million values as NIST SP 800-38G requires
+const minTokenDigits = 6
+
+// ff31TweakSize is the size of an FF3-1 tweak, in bytes
+const ff31TweakSize = 7
+
+// Tokenizer replaces card numbers with tokens of the same length, made of
+// digits, with format-preserving encryption: tokens can be stored in place
+// of numbers, and only turned back into numbers with the key. The first six
+// and last four digits can be kept, so that LastFour and company detection
+// still work on tokens. It is not modified once built, so a Tokenizer is safe
+// for concurrent use.
+type Tokenizer struct {
+	algorithm        FPE
+	block            cipher.Block
+	tweak            []byte
+	preserveBIN      bool
+	preserveLastFour bool
+	luhnFailure      bool
+}
+
+// TokenizerOption configures a Tokenizer built by NewTokenizer
+type TokenizerOption func(*Tokenizer)
+
+// WithTweak sets the tweak of the encryption, empty by default. FF3-1 tweaks
+// are 7 bytes long.
+func WithTweak(tweak []byte) TokenizerOption {
+	return func(t *Tokenizer) {
+		t.tweak = append([]byte(nil), tweak...)
+	}
+}
+
+// WithPreserveBIN sets whether the first six digits are kept as is
+func WithPreserveBIN(preserve bool) TokenizerOption {
+	return func(t *Tokenizer) {
+		t.preserveBIN = preserve
+	}
+}
+
+// WithPreserveLastFour sets whether the last four digits are kept as is
+func WithPreserveLastFour(preserve bool) TokenizerOption {
+	return func(t *Tokenizer) {
+		t.preserveLastFour = preserve
+	}
+}
+
+// WithLuhnFailure sets whether tokens always fail the Luhn algorithm, so
+// that ValidateNumber never mistakes them for card numbers. One digit of the
+// number is then left out of the encryption, as it can be computed back from
+// the others, and set to fail the Luhn algorithm; only numbers passing it
+// can be tokenized.
+func WithLuhnFailure(fail bool) TokenizerOption {
+	return func(t *Tokenizer) {
+		t.luhnFailure = fail
+	}
+}
+
+// NewTokenizer returns a tokenizer encrypting with the algorithm under the
+// AES key, of 16, 24 or 32 bytes
+func NewTokenizer(algorithm FPE, key []byte, options ...TokenizerOption) (*Tokenizer, error) {
+	t := &Tokenizer{algorithm: algorithm}
+	for _, option := range options {
+		option(t)
+	}
+
+	var err error
+	switch algorithm {
+	case FF1:
+		t.block, err = aes.NewCipher(key)
+	case FF31:
+		if t.tweak == nil {
+			t.tweak = make([]byte, ff31TweakSize)
+		}
+		if len(t.tweak) != ff31TweakSize {
+			return nil, fmt.Errorf("FF3-1 tweak is %d bytes long, must be %d", len(t.tweak), ff31TweakSize)
+		}
+		t.block, err = aes.NewCipher(reverseBytes(key))
+	default:
+		return nil, fmt.Errorf("Unknown format-preserving encryption algorithm %v", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid tokenizer key: %v", err)
+	}
+
+	return t, nil
+}
+
+// Algorithm returns the algorithm of the tokenizer
+func (t *Tokenizer) Algorithm() FPE {
+	return t.algorithm
+}
+
+// Tokenize returns the token of the card's number, see TokenizeNumber
+func (t *Tokenizer) Tokenize(c Card) (string, error) {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return "", err
+	}
+
+	return t.tokenize(number, false)
+}
+
+// TokenizeNumber returns the token of the number, normalized first: digits
+// of the same length, keeping the digits the options preserve. The number
+// must be 13 to 19 digits long.
+func (t *Tokenizer) TokenizeNumber(number string) (string, error) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return "", err
+	}
+
+	return t.tokenize(number, false)
+}
+
+// Detokenize returns the number of the token, normalized first
+func (t *Tokenizer) Detokenize(token string) (string, error) {
+	token, err := NormalizeNumber(token)
+	if err != nil {
+		return "", err
+	}
+
+	return t.tokenize(token, true)
+}
+
+// tokenize encrypts, or decrypts, the digits of the normalized number
+// between the preserved ones
+func (t *Tokenizer) tokenize(number string, decrypt bool) (string, error) {
+	if !hasValidLength(number) {
+		return "", newValidationError(CodeInvalidLength, FieldNumber, ErrInvalidLength)
+	}
+
+	if t.luhnFailure && !decrypt && !luhn(number) {
+		return "", newValidationError(CodeInvalidNumber, FieldNumber, ErrInvalidNumber)
+	}
+
+	start, end := 0, len(number)
+	if t.preserveBIN {
+		start = 6
+	}
+	if t.preserveLastFour {
+		end -= 4
+	}
+
+	// the free digit is left out of the encryption, and computed from the others
+	free := -1
+	if t.luhnFailure {
+		end--
+		free = end
+	}
+
+	if end-start < minTokenDigits {
+		return "", fmt.Errorf("Number of %d digits is too short to tokenize with these options: %d digits would be encrypted, must be at least %d",
+			len(number), end-start, minTokenDigits)
+	}
+
+	b := []byte(number)
+	copy(b[start:end], t.crypt(number[start:end], decrypt))
+
+	if free >= 0 {
+		b[free] = byte('0' + luhnDigitAt(string(b), free))
+		if !decrypt {
+			b[free] = '0' + (b[free]-'0'+1)%10
+		}
+	}
+
+	return string(b), nil
+}
+
+func (t *Tokenizer) crypt(x string, decrypt bool) string {
+	if t.algorithm == FF31 {
+		var tweak [ff31TweakSize]byte
+		copy(tweak[:], t.tweak)
+		return ff3(t.block, ff31Tweak(tweak), x, decrypt)
+	}
+
+	return ff1(t.block, t.tweak, x, decrypt)
+}
+
+// luhnDigitAt returns the digit at position i of the normalized number making
+// it pass the Luhn algorithm
+func luhnDigitAt(number string, i int) int {
+	b := []byte(number)
+	for d := 0; d < 10; d++ {
+		b[i] = byte('0' + d)
+		if luhn(string(b)) {
+			return d
+		}
+	}
+
+	return 0
+}
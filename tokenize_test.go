@@ -0,0 +1,187 @@
+package creditcard
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func TestFPE(t *testing.T) {
+	Convey("FF1 should match the NIST SP 800-38G samples", t, func() {
+		samples := []struct{ key, tweak, plaintext, ciphertext string }{
+			{"2B7E151628AED2A6ABF7158809CF4F3C", "", "0123456789", "2433477484"},
+			{"2B7E151628AED2A6ABF7158809CF4F3C", "39383736353433323130", "0123456789", "6124200773"},
+			{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "", "0123456789", "2830668132"},
+			{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "39383736353433323130", "0123456789", "2496655549"},
+			{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "", "0123456789", "6657667009"},
+			{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "39383736353433323130", "0123456789", "1001623463"},
+		}
+
+		for _, sample := range samples {
+			block, err := aes.NewCipher(mustHex(sample.key))
+			So(err, ShouldBeNil)
+
+			So(ff1(block, mustHex(sample.tweak), sample.plaintext, false), ShouldEqual, sample.ciphertext)
+			So(ff1(block, mustHex(sample.tweak), sample.ciphertext, true), ShouldEqual, sample.plaintext)
+		}
+	})
+
+	Convey("FF3 should match the NIST SP 800-38G samples", t, func() {
+		samples := []struct{ key, tweak, plaintext, ciphertext string }{
+			{"EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", "890121234567890000", "750918814058654607"},
+			{"EF4359D8D580AA4F7F036D6F04FC6A94", "9A768A92F60E12D8", "890121234567890000", "018989839189395384"},
+			{"EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", "89012123456789000000789000000", "48598367162252569629397416226"},
+			{"EF4359D8D580AA4F7F036D6F04FC6A94", "0000000000000000", "89012123456789000000789000000", "34695224821734535122613701434"},
+		}
+
+		for _, sample := range samples {
+			block, err := aes.NewCipher(reverseBytes(mustHex(sample.key)))
+			So(err, ShouldBeNil)
+
+			var tweak [8]byte
+			copy(tweak[:], mustHex(sample.tweak))
+
+			So(ff3(block, tweak, sample.plaintext, false), ShouldEqual, sample.ciphertext)
+			So(ff3(block, tweak, sample.ciphertext, true), ShouldEqual, sample.plaintext)
+		}
+	})
+
+	Convey("FF3-1 should expand its 56 bit tweak", t, func() {
+		var tweak [7]byte
+		copy(tweak[:], mustHex("D8E7920AFA330A"))
+
+		So(ff31Tweak(tweak), ShouldResemble, [8]byte{0xD8, 0xE7, 0x92, 0x00, 0xFA, 0x33, 0x0A, 0xA0})
+	})
+}
+
+func TestTokenizer(t *testing.T) {
+	key := mustHex("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	for _, algorithm := range []FPE{FF1, FF31} {
+		Convey("Should tokenize numbers with "+algorithm.String(), t, func() {
+			generator := NewGenerator(42)
+
+			Convey("Tokens should have the length of the number and decrypt back", func() {
+				tokenizer, err := NewTokenizer(algorithm, key)
+				So(err, ShouldBeNil)
+
+				for length := 13; length <= 19; length++ {
+					number, err := generator.GenerateLength("visa", length)
+					So(err, ShouldBeNil)
+
+					token, err := tokenizer.TokenizeNumber(number)
+					So(err, ShouldBeNil)
+					So(len(token), ShouldEqual, length)
+					So(token, ShouldNotEqual, number)
+
+					again, _ := tokenizer.TokenizeNumber(number)
+					So(again, ShouldEqual, token)
+
+					detokenized, err := tokenizer.Detokenize(token)
+					So(err, ShouldBeNil)
+					So(detokenized, ShouldEqual, number)
+				}
+			})
+
+			Convey("Tokens can keep the BIN and last four", func() {
+				tokenizer, err := NewTokenizer(algorithm, key, WithPreserveBIN(true), WithPreserveLastFour(true))
+				So(err, ShouldBeNil)
+
+				card := Card{Number: "4556 9748 5040 3706"}
+				token, err := tokenizer.Tokenize(card)
+				So(err, ShouldBeNil)
+				So(token, ShouldStartWith, "455697")
+				So(token, ShouldEndWith, "3706")
+				So(token, ShouldNotEqual, "4556974850403706")
+
+				tokenized := Card{Number: token}
+				lastFour, err := tokenized.LastFour()
+				So(err, ShouldBeNil)
+				So(lastFour, ShouldEqual, "3706")
+
+				number, err := tokenizer.Detokenize(token)
+				So(err, ShouldBeNil)
+				So(number, ShouldEqual, "4556974850403706")
+			})
+
+			Convey("Tokens can be forced to fail the Luhn algorithm", func() {
+				tokenizer, err := NewTokenizer(algorithm, key, WithPreserveLastFour(true), WithLuhnFailure(true))
+				So(err, ShouldBeNil)
+
+				for i := 0; i < 200; i++ {
+					number, _ := generator.Generate("mastercard")
+
+					token, err := tokenizer.TokenizeNumber(number)
+					So(err, ShouldBeNil)
+					So(token[len(token)-4:], ShouldEqual, number[len(number)-4:])
+
+					card := Card{Number: token}
+					So(card.ValidateNumber(), ShouldBeFalse)
+
+					detokenized, err := tokenizer.Detokenize(token)
+					So(err, ShouldBeNil)
+					So(detokenized, ShouldEqual, number)
+				}
+
+				_, err = tokenizer.TokenizeNumber("4556974850403707")
+				So(ErrorCodeOf(err), ShouldEqual, CodeInvalidNumber)
+			})
+		})
+	}
+
+	Convey("Tokens should depend on the key and tweak", t, func() {
+		tokenizer, _ := NewTokenizer(FF1, key)
+		token, _ := tokenizer.TokenizeNumber("4556974850403706")
+
+		other, _ := NewTokenizer(FF1, mustHex("EF4359D8D580AA4F7F036D6F04FC6A94"))
+		otherToken, _ := other.TokenizeNumber("4556974850403706")
+		So(otherToken, ShouldNotEqual, token)
+
+		tweaked, _ := NewTokenizer(FF1, key, WithTweak([]byte("merchant-42")))
+		tweakedToken, _ := tweaked.TokenizeNumber("4556974850403706")
+		So(tweakedToken, ShouldNotEqual, token)
+	})
+
+	Convey("Should reject invalid options and numbers", t, func() {
+		_, err := NewTokenizer(FF1, []byte("short"))
+		So(err, ShouldNotBeNil)
+		_, err = NewTokenizer(FF31, key, WithTweak([]byte("8 bytes!")))
+		So(err, ShouldNotBeNil)
+		_, err = NewTokenizer(FPE(7), key)
+		So(err, ShouldNotBeNil)
+		So(FPE(7).String(), ShouldEqual, "FPE(7)")
+
+		tokenizer, _ := NewTokenizer(FF1, key, WithPreserveBIN(true), WithPreserveLastFour(true), WithLuhnFailure(true))
+		_, err = tokenizer.TokenizeNumber("4556974850403706")
+		So(err, ShouldNotBeNil)
+		So(strings.Contains(err.Error(), "too short"), ShouldBeTrue)
+
+		number, _ := NewGenerator(1).GenerateLength("visa", 17)
+		token, err := tokenizer.TokenizeNumber(number)
+		So(err, ShouldBeNil)
+		detokenized, _ := tokenizer.Detokenize(token)
+		So(detokenized, ShouldEqual, number)
+
+		_, err = tokenizer.TokenizeNumber("455697485040")
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidLength)
+		_, err = tokenizer.TokenizeNumber("4556x974850403706")
+		So(ErrorCodeOf(err), ShouldEqual, CodeInvalidCharacter)
+
+		wiped := Card{Number: "4556974850403706"}
+		wiped.Wipe()
+		_, err = tokenizer.Tokenize(wiped)
+		So(ErrorCodeOf(err), ShouldEqual, CodeWiped)
+	})
+}
@@ -0,0 +1,443 @@
+package creditcard
+
+import (
+	"time"
+)
+
+// Validator validates cards against its own clock, registry and options. It
+// is not modified once built, so a Validator is safe for concurrent use.
+// The Card methods use a default Validator reading the system clock and the
+// DefaultRegistry.
+type Validator struct {
+	now      func() time.Time
+	registry *Registry
+	opts     ValidateOptions
+}
+
+// Option configures a Validator built by NewValidator
+type Option func(*Validator)
+
+// WithClock makes the validator check expiration dates and resolve two digit
+// years against the given clock instead of time.Now, e.g. to validate a card
+// at the date of a past transaction
+func WithClock(now func() time.Time) Option {
+	return func(v *Validator) {
+		v.now = now
+	}
+}
+
+// WithRegistry makes the validator detect companies from the given registry
+// instead of the DefaultRegistry
+func WithRegistry(r *Registry) Option {
+	return func(v *Validator) {
+		v.registry = r
+	}
+}
+
+// WithTestCards sets whether the test cards of the given catalogs, or of the
+// DefaultTestCatalogs when none are given, go through validation
+func WithTestCards(allow bool, catalogs ...string) Option {
+	return func(v *Validator) {
+		v.opts.AllowTestNumbers = allow
+		v.opts.TestCatalogs = append([]string(nil), catalogs...)
+	}
+}
+
+// WithAcceptedBrands restricts validation to the cards of the companies with
+// the given short names
+func WithAcceptedBrands(shorts ...string) Option {
+	return func(v *Validator) {
+		v.opts.AcceptedBrands = append([]string(nil), shorts...)
+	}
+}
+
+// WithStrict sets whether numbers must be made of ASCII digits only, without
+// separators, and belong to a known company
+func WithStrict(strict bool) Option {
+	return func(v *Validator) {
+		v.opts.Strict = strict
+	}
+}
+
+// WithRequireKnownBrand sets whether numbers must belong to a known company
+func WithRequireKnownBrand(require bool) Option {
+	return func(v *Validator) {
+		v.opts.RequireKnownBrand = require
+	}
+}
+
+// WithStrictDigits sets whether numbers must be made of ASCII digits only,
+// without separators
+func WithStrictDigits(strict bool) Option {
+	return func(v *Validator) {
+		v.opts.StrictDigits = strict
+	}
+}
+
+// WithMaskPolicy adds the card's number, masked with the policy, to the
+// errors about the number
+func WithMaskPolicy(policy MaskPolicy) Option {
+	return func(v *Validator) {
+		v.opts.MaskPolicy = &policy
+	}
+}
+
+// WithSkip turns off the given checks, e.g. WithSkip(CheckCVV) for
+// card-on-file charges
+func WithSkip(checks Check) Option {
+	return func(v *Validator) {
+		v.opts.Skip |= checks
+	}
+}
+
+// WithOptions replaces the validator's options
+func WithOptions(opts ValidateOptions) Option {
+	return func(v *Validator) {
+		opts.TestCatalogs = append([]string(nil), opts.TestCatalogs...)
+		opts.AcceptedBrands = append([]string(nil), opts.AcceptedBrands...)
+		v.opts = opts
+	}
+}
+
+// NewValidator returns a validator configured by the given options
+func NewValidator(options ...Option) *Validator {
+	v := &Validator{now: time.Now}
+	for _, option := range options {
+		option(v)
+	}
+
+	return v
+}
+
+var defaultValidator = NewValidator()
+
+// Registry returns the registry the validator detects companies from
+func (v *Validator) Registry() *Registry {
+	if v.registry == nil {
+		return DefaultRegistry
+	}
+
+	return v.registry
+}
+
+// Now returns the current time of the validator's clock
+func (v *Validator) Now() time.Time {
+	return v.now()
+}
+
+// withOptions returns a copy of the validator using the given options
+func (v *Validator) withOptions(opts ValidateOptions) *Validator {
+	w := *v
+	w.opts = opts
+	return &w
+}
+
+// Validate returns nil or an error describing why the card didn't validate,
+// checking its expiration date, CVV and number like Card.Validate, along with
+// the validator's options
+func (v *Validator) Validate(c Card) error {
+	err := v.validate(c)
+	if vErr, ok := err.(*ValidationError); ok {
+		v.maskNumber(c, vErr)
+	}
+
+	return err
+}
+
+func (v *Validator) validate(c Card) error {
+	catalogs, err := v.opts.testCatalogs()
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkNumber(); err != nil {
+		return err
+	}
+
+	if v.opts.runs(CheckExpiration) {
+		if err := v.ValidateExpiration(c); err != nil {
+			return err
+		}
+	}
+
+	if v.opts.runs(CheckCVV) || v.opts.RequireCVV {
+		if err := v.ValidateCVV(c); err != nil {
+			return err
+		}
+	}
+
+	number, err := v.normalizeNumber(c.number())
+	if err != nil {
+		return err
+	}
+
+	card, found := TestCard{}, false
+	if v.opts.runs(CheckTestNumber) {
+		card, found = FindTestCard(number, catalogs...)
+	}
+
+	if found {
+		if !v.opts.AllowTestNumbers {
+			return newTestCardError(card)
+		}
+	} else {
+		if v.opts.runs(CheckLength) {
+			if err := v.Registry().validateLength(number); err != nil {
+				return err
+			}
+		}
+
+		if v.opts.runs(CheckChecksum) && hasValidLength(number) && !v.Registry().hasValidChecksum(number) {
+			return newValidationError(CodeInvalidNumber, FieldNumber, ErrInvalidNumber)
+		}
+	}
+
+	return v.checkBrand(number)
+}
+
+// maskNumber sets the card's masked number on errors about the number when
+// the options chose a MaskPolicy
+func (v *Validator) maskNumber(c Card, err *ValidationError) {
+	if v.opts.MaskPolicy == nil || err.Field != FieldNumber {
+		return
+	}
+
+	if masked, mErr := MaskNumber(c.number(), *v.opts.MaskPolicy); mErr == nil {
+		err.Number = masked
+	}
+}
+
+// ValidateAll runs every check Validate does, without stopping at the first
+// failure, and returns a report listing all of them, see Card.ValidateAll.
+// It only fails when the validator's options are invalid.
+func (v *Validator) ValidateAll(c Card) (*Report, error) {
+	catalogs, err := v.opts.testCatalogs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	if err := c.checkNumber(); err != nil {
+		report.addError(err)
+		return report, nil
+	}
+
+	defer func() {
+		for _, err := range append(report.Errors, report.Warnings...) {
+			v.maskNumber(c, err)
+		}
+	}()
+
+	if v.opts.runs(CheckExpiration) {
+		if err := v.ValidateExpiration(c); err != nil {
+			report.addError(err)
+		}
+	}
+
+	if v.opts.runs(CheckCVV) || v.opts.RequireCVV {
+		if err := v.ValidateCVV(c); err != nil {
+			report.addError(err)
+		}
+	}
+
+	number, err := v.normalizeNumber(c.number())
+	if err != nil {
+		report.addError(err)
+		return report, nil
+	}
+
+	company, err := v.Registry().Detect(number)
+	if err != nil {
+		if v.opts.requireKnownBrand() {
+			report.addError(err)
+		} else {
+			report.addWarning(err)
+		}
+	}
+	report.Company = company
+
+	if card, found := FindTestCard(number, catalogs...); found && v.opts.runs(CheckTestNumber) {
+		report.TestCard = &card
+		if v.opts.AllowTestNumbers {
+			report.addWarning(newTestCardError(card))
+		} else {
+			report.addError(newTestCardError(card))
+		}
+	}
+
+	if v.opts.runs(CheckLength) {
+		if err := v.Registry().validateLength(number); err != nil {
+			report.addError(err)
+		}
+	}
+
+	if v.opts.runs(CheckChecksum) && hasValidLength(number) && !v.Registry().hasValidChecksum(number) {
+		report.addError(newValidationError(CodeInvalidNumber, FieldNumber, ErrInvalidNumber))
+	}
+
+	if err := v.checkAccepted(number); err != nil {
+		report.addError(err)
+	}
+
+	return report, nil
+}
+
+// normalizeNumber normalizes the number, see NormalizeNumber. With strict
+// digits, only numbers which are already normalized are accepted.
+func (v *Validator) normalizeNumber(number string) (string, error) {
+	normalized, err := NormalizeNumber(number)
+	if err != nil || !v.opts.strictDigits() || normalized == number {
+		return normalized, err
+	}
+
+	for i, r := range []rune(number) {
+		if r < '0' || r > '9' {
+			return "", newValidationError(CodeInvalidCharacter, FieldNumber, &CharacterError{Position: i, Char: r})
+		}
+	}
+
+	return normalized, nil
+}
+
+// checkBrand makes sure the number's company is known when required, and
+// that it is accepted
+func (v *Validator) checkBrand(number string) error {
+	if v.opts.requireKnownBrand() {
+		if _, err := v.Registry().Detect(number); err != nil {
+			return err
+		}
+	}
+
+	return v.checkAccepted(number)
+}
+
+// checkAccepted makes sure one of the companies of the number is accepted.
+// Co-badged cards are accepted when any of their companies is.
+func (v *Validator) checkAccepted(number string) error {
+	if len(v.opts.AcceptedBrands) == 0 {
+		return nil
+	}
+
+	matches, _ := v.Registry().DetectAll(number)
+	for _, m := range matches {
+		if v.opts.accepts(m.Company.Short) {
+			return nil
+		}
+	}
+
+	return newValidationError(CodeBrandNotAccepted, FieldNumber, ErrBrandNotAccepted)
+}
+
+// ValidateExpiration checks the card is not expired at the validator's clock,
+// nor expiring outside of the horizon set by MinExpiryMonths and MaxExpiryMonths
+func (v *Validator) ValidateExpiration(c Card) error {
+	if err := c.checkNumber(); err != nil {
+		return err
+	}
+
+	expiry, err := v.Expiry(c)
+	if err != nil {
+		return err
+	}
+
+	timeNow := v.now().UTC()
+	if expiry.IsExpiredAt(timeNow) {
+		if expiry.Year < timeNow.Year() {
+			return newValidationError(CodeExpired, FieldYear, ErrExpired)
+		}
+		return newValidationError(CodeExpired, FieldMonth, ErrExpired)
+	}
+
+	remaining := expiry.MonthsRemaining(timeNow)
+	if remaining < v.opts.MinExpiryMonths || (v.opts.MaxExpiryMonths > 0 && remaining > v.opts.MaxExpiryMonths) {
+		return newValidationError(CodeExpiryOutOfRange, FieldExpiry, ErrExpiryOutOfRange)
+	}
+
+	return nil
+}
+
+// ValidateCVV checks the card's CVV against its company, see Card.ValidateCVV.
+// With the RequireCVV option, the CVV must be provided.
+func (v *Validator) ValidateCVV(c Card) error {
+	if err := c.checkCVV(); err != nil {
+		return err
+	}
+
+	s, err := v.Registry().detect(c.normalizedNumber())
+	known := err == nil
+
+	cvv, err := NormalizeCVV(c.cvv())
+	if err != nil {
+		return err
+	}
+
+	if v.opts.RequireCVV && (c.CvvPresence != CVVProvided || cvv == "") {
+		return newValidationError(CodeCVVRequired, FieldCVV, ErrCVVRequired)
+	}
+
+	switch c.CvvPresence {
+	case CVVProvided:
+	case CVVNotOnCard:
+		if known && !s.CVVOptional {
+			return newValidationError(CodeCVVRequired, FieldCVV, ErrCVVRequired)
+		}
+		fallthrough
+	case CVVNotProvided, CVVIllegible:
+		if cvv != "" {
+			return newValidationError(CodeInvalidCVV, FieldCVV, ErrInvalidCVV)
+		}
+		return nil
+	default:
+		return newValidationError(CodeInvalidCVV, FieldCVV, ErrInvalidCVV)
+	}
+
+	if known && s.CVVLength > 0 {
+		if len(cvv) != s.CVVLength {
+			return newValidationError(CodeInvalidCVV, FieldCVV, ErrInvalidCVV)
+		}
+		return nil
+	}
+
+	if len(cvv) < 3 || len(cvv) > 4 {
+		return newValidationError(CodeInvalidCVV, FieldCVV, ErrInvalidCVV)
+	}
+
+	return nil
+}
+
+// ValidateLength checks the length of the card's number, see Card.ValidateLength
+func (v *Validator) ValidateLength(c Card) error {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return err
+	}
+
+	return v.Registry().validateLength(number)
+}
+
+// MethodValidate returns the company of the card's number. With accepted
+// brands, it is the first accepted company of a co-badged card, and numbers
+// of other companies fail with ErrBrandNotAccepted.
+func (v *Validator) MethodValidate(c Card) (Company, error) {
+	number, err := c.NormalizedNumber()
+	if err != nil {
+		return Company{"", ""}, err
+	}
+
+	if len(v.opts.AcceptedBrands) == 0 {
+		return v.Registry().Detect(number)
+	}
+
+	matches, err := v.Registry().DetectAll(number)
+	if err != nil {
+		return Company{"", ""}, err
+	}
+
+	for _, m := range matches {
+		if v.opts.accepts(m.Company.Short) {
+			return m.Company, nil
+		}
+	}
+
+	return Company{"", ""}, newValidationError(CodeBrandNotAccepted, FieldNumber, ErrBrandNotAccepted)
+}
@@ -0,0 +1,197 @@
+package creditcard
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidator(t *testing.T) {
+	Convey("Should validate against its own clock", t, func() {
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2001"}
+
+		So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeExpired)
+
+		v := NewValidator(WithClock(func() time.Time {
+			return time.Date(2001, 2, 28, 12, 0, 0, 0, time.UTC)
+		}))
+		So(v.Validate(card), ShouldBeNil)
+		So(v.Now().Year(), ShouldEqual, 2001)
+	})
+
+	Convey("Should apply its test card policy", t, func() {
+		card := Card{Number: "4111111145551142", Cvv: "111", Month: "02", Year: "2099"}
+
+		So(NewValidator().Validate(card), ShouldBeNil)
+		So(ErrorCodeOf(NewValidator(WithTestCards(false, "adyen")).Validate(card)), ShouldEqual, CodeTestNumber)
+		So(NewValidator(WithTestCards(true, "adyen")).Validate(card), ShouldBeNil)
+
+		_, err := NewValidator(WithTestCards(false, "nope")).ValidateAll(card)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Should only accept the given brands", t, func() {
+		v := NewValidator(WithAcceptedBrands("visa", "mastercard"))
+
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		So(v.Validate(card), ShouldBeNil)
+
+		card = Card{Number: "378734493671000", Cvv: "1111", Month: "02", Year: "2099"}
+		err := v.Validate(card)
+		So(ErrorCodeOf(err), ShouldEqual, CodeBrandNotAccepted)
+		So(err.Error(), ShouldEqual, "Credit card company is not accepted")
+
+		report, err := v.ValidateAll(card)
+		So(err, ShouldBeNil)
+		So(report.Errors[0].Code, ShouldEqual, CodeBrandNotAccepted)
+
+		Convey("Co-badged cards are accepted when any of their companies is", func() {
+			card := Card{Number: "4571000000000001", Cvv: "111", Month: "02", Year: "2099"}
+			So(NewValidator(WithAcceptedBrands("dankort")).Validate(card), ShouldBeNil)
+			So(NewValidator(WithAcceptedBrands("visa")).Validate(card), ShouldBeNil)
+		})
+	})
+
+	Convey("Should reject separators and unknown companies when strict", t, func() {
+		v := NewValidator(WithStrict(true))
+
+		card := Card{Number: "4556 9748 5040 3706", Cvv: "111", Month: "02", Year: "2099"}
+		So(NewValidator().Validate(card), ShouldBeNil)
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeInvalidCharacter)
+
+		card = Card{Number: "9999999999999995", Cvv: "111", Month: "02", Year: "2099"}
+		So(NewValidator().Validate(card), ShouldBeNil)
+		So(ErrorCodeOf(v.Validate(card)), ShouldEqual, CodeUnknownMethod)
+
+		report, _ := v.ValidateAll(card)
+		So(report.Errors[0].Code, ShouldEqual, CodeUnknownMethod)
+		So(len(report.Warnings), ShouldEqual, 0)
+	})
+
+	Convey("Should detect companies from its registry", t, func() {
+		r := NewRegistry()
+		So(r.Register(Scheme{Company: Company{"acme", "Acme"}, Ranges: []IINRange{Prefix("99")}, CVVLength: 3}), ShouldBeNil)
+		v := NewValidator(WithRegistry(r))
+
+		card := Card{Number: "9999999999999995", Cvv: "111", Month: "02", Year: "2099"}
+		company, err := v.MethodValidate(card)
+		So(err, ShouldBeNil)
+		So(company.Short, ShouldEqual, "acme")
+		So(v.Registry(), ShouldEqual, r)
+
+		card = Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+		_, err = v.MethodValidate(card)
+		So(ErrorCodeOf(err), ShouldEqual, CodeUnknownMethod)
+	})
+
+	Convey("Should be safe for concurrent use", t, func() {
+		v := NewValidator(WithAcceptedBrands("visa"), WithClock(func() time.Time {
+			return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		}))
+		card := Card{Number: "4556974850403706", Cvv: "111", Month: "02", Year: "2099"}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 50)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = v.Validate(card)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			So(err, ShouldBeNil)
+		}
+	})
+}
+
+func TestValidateOptions(t *testing.T) {
+	Convey("Should keep the variadic flag working", t, func() {
+		card := Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+
+		So(card.Validate(), ShouldNotBeNil)
+		So(card.Validate(true), ShouldBeNil)
+		So(card.ValidateWith(ValidateOptions{}), ShouldResemble, card.Validate())
+		So(card.ValidateWith(ValidateOptions{AllowTestNumbers: true}), ShouldBeNil)
+	})
+
+	Convey("Should skip the given checks", t, func() {
+		card := Card{Number: "4556974850403706", Month: "02", Year: "2001"}
+
+		So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeExpired)
+		So(ErrorCodeOf(card.ValidateWith(ValidateOptions{Skip: CheckExpiration})), ShouldEqual, CodeInvalidCVV)
+		So(card.ValidateWith(ValidateOptions{Skip: CheckExpiration | CheckCVV}), ShouldBeNil)
+
+		report, _ := card.ValidateAllWith(ValidateOptions{Skip: CheckCVV})
+		So(len(report.Errors), ShouldEqual, 1)
+		So(report.Errors[0].Code, ShouldEqual, CodeExpired)
+
+		Convey("Including the number checks", func() {
+			card := Card{Number: "4556974850403707", Cvv: "111", Month: "02", Year: "2099"}
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeInvalidNumber)
+			So(card.ValidateWith(ValidateOptions{Skip: CheckChecksum}), ShouldBeNil)
+
+			card = Card{Number: "42424242424242420", Cvv: "111", Month: "02", Year: "2099"}
+			So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeWrongLengthForCompany)
+			So(ErrorCodeOf(card.ValidateWith(ValidateOptions{Skip: CheckLength})), ShouldEqual, CodeInvalidNumber)
+
+			Convey("With the same code as ValidateAll and ValidateLength", func() {
+				card := Card{Number: "424242424242", Cvv: "111", Month: "02", Year: "2099"}
+				report := card.ValidateAll()
+
+				So(ErrorCodeOf(card.Validate()), ShouldEqual, CodeInvalidLength)
+				So(ErrorCodeOf(card.ValidateLength()), ShouldEqual, CodeInvalidLength)
+				So(report.Errors[0].Code, ShouldEqual, CodeInvalidLength)
+			})
+
+			card = Card{Number: "4242424242424242", Cvv: "111", Month: "02", Year: "2099"}
+			So(card.ValidateWith(ValidateOptions{Skip: CheckTestNumber}), ShouldBeNil)
+
+			report, _ := card.ValidateAllWith(ValidateOptions{Skip: CheckTestNumber})
+			So(report.Valid(), ShouldBeTrue)
+			So(report.TestCard, ShouldBeNil)
+
+			card = Card{Number: "4111111111111", Cvv: "111", Month: "02", Year: "2099"}
+			So(card.ValidateWith(ValidateOptions{Skip: CheckLength | CheckTestNumber}), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Should split strictness into digits and brand", t, func() {
+		card := Card{Number: "4556-9748-5040-3706", Cvv: "111", Month: "02", Year: "2099"}
+		So(ErrorCodeOf(card.ValidateWith(ValidateOptions{StrictDigits: true})), ShouldEqual, CodeInvalidCharacter)
+		So(card.ValidateWith(ValidateOptions{RequireKnownBrand: true}), ShouldBeNil)
+
+		card = Card{Number: "9999999999999995", Cvv: "111", Month: "02", Year: "2099"}
+		So(card.ValidateWith(ValidateOptions{StrictDigits: true}), ShouldBeNil)
+		So(ErrorCodeOf(card.ValidateWith(ValidateOptions{RequireKnownBrand: true})), ShouldEqual, CodeUnknownMethod)
+		So(ErrorCodeOf(NewValidator(WithRequireKnownBrand(true)).Validate(card)), ShouldEqual, CodeUnknownMethod)
+	})
+
+	Convey("Should mask the number in errors with the chosen policy", t, func() {
+		card := Card{Number: "4556974850403707", Cvv: "111", Month: "02", Year: "2099"}
+
+		err := card.Validate()
+		So(err.Error(), ShouldEqual, "Invalid credit card number")
+
+		err = card.ValidateWith(ValidateOptions{MaskPolicy: &MaskLastFour})
+		So(err.Error(), ShouldEqual, "Invalid credit card number (************3707)")
+
+		var vErr *ValidationError
+		So(errors.As(err, &vErr), ShouldBeTrue)
+		So(vErr.Number, ShouldEqual, "************3707")
+
+		report, _ := NewValidator(WithMaskPolicy(MaskFirstSixLastFour)).ValidateAll(card)
+		So(report.Errors[0].Number, ShouldEqual, "455697******3707")
+
+		Convey("Errors about other fields are left alone", func() {
+			card := Card{Number: "4556974850403706", Cvv: "11", Month: "02", Year: "2099"}
+			err := card.ValidateWith(ValidateOptions{MaskPolicy: &MaskLastFour})
+			So(err.Error(), ShouldEqual, "Invalid CVV")
+		})
+	})
+}
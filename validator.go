@@ -1,109 +1,203 @@
 package creditcard
 
-func isAmex(ccDigits digits) bool {
-	return matchesValue(ccDigits.At(2), []int{34, 37})
-}
-
-func isBankCard(ccDigits digits) bool {
-	return ccDigits.At(4) == 5610 || isInBetween(ccDigits.At(6), 560221, 560225)
-}
-
-func isCabal(ccDigits digits) bool {
-	atSix := ccDigits.At(6)
-
-	return matchesValue(atSix, []int{604400, 627170, 603522, 589657}) ||
-		isInBetween(atSix, 604201, 604219) ||
-		isInBetween(atSix, 604300, 604399)
-}
-
-func isUnionPay(ccDigits digits) bool {
-	return matchesValue(ccDigits.At(2), []int{62, 81})
-}
-
-func isDinersClubCarteBlanche(ccDigits digits, ccLen int) bool {
-	return isInBetween(ccDigits.At(3), 300, 305) && ccLen == 14
-}
-
-func isDinersClubEnroute(ccDigits digits) bool {
-	return matchesValue(ccDigits.At(4), []int{2014, 2149})
-}
-
-func isDinersClubInternational(ccDigits digits, ccLen int) bool {
-	checkThree := isInBetween(ccDigits.At(3), 300, 305) || ccDigits.At(3) == 309
-	checkTwoo := matchesValue(ccDigits.At(2), []int{36, 38, 39})
-
-	return (checkThree || checkTwoo) && ccLen <= 14
-}
-
-func isDiscover(ccDigits digits) bool {
-	return ccDigits.At(4) == 6011 ||
-		isInBetween(ccDigits.At(6), 622126, 622925) ||
-		isInBetween(ccDigits.At(3), 644, 649) ||
-		ccDigits.At(2) == 65
-}
-
-func isElo(ccDigits digits) bool {
-	atFour := ccDigits.At(4)
-	atSix := ccDigits.At(6)
-
-	return matchesValue(atFour, []int{4011, 4576}) ||
-		matchesValue(atSix, []int{431274, 438935, 451416, 457393, 457631, 457632, 504175, 627780, 636297, 636368, 636369}) ||
-		isInBetween(atSix, 506699, 506778) ||
-		isInBetween(atSix, 509000, 509999) ||
-		isInBetween(atSix, 650031, 650051) ||
-		isInBetween(atSix, 650035, 650033) ||
-		isInBetween(atSix, 650405, 650439) ||
-		isInBetween(atSix, 650485, 650538) ||
-		isInBetween(atSix, 650541, 650598) ||
-		isInBetween(atSix, 650700, 650718) ||
-		isInBetween(atSix, 650720, 650727) ||
-		isInBetween(atSix, 650901, 650920) ||
-		isInBetween(atSix, 651652, 651679) ||
-		isInBetween(atSix, 655000, 655019) ||
-		isInBetween(atSix, 655021, 655021)
-}
-
-func isHipercard(ccDigits digits) bool {
-	return matchesValue(ccDigits.At(6), []int{606282, 637095, 637568, 637599, 637609, 637612})
-}
-
-func isInterpayment(ccDigits digits, ccLen int) bool {
-	return ccDigits.At(3) == 636 && isInBetween(ccLen, 16, 19)
-}
-
-func isInstapayment(ccDigits digits, ccLen int) bool {
-	return isInBetween(ccDigits.At(3), 637, 639) && ccLen == 16
-}
-
-func isJCB(ccDigits digits) bool {
-	return isInBetween(ccDigits.At(4), 3528, 3589)
-}
-
-func isNaranja(ccDigits digits) bool {
-	return ccDigits.At(6) == 589562
-}
-
-func isMaestro(c *Card, ccDigits digits) bool {
-	return matchesValue(ccDigits.At(4), []int{5018, 5020, 5038, 5612, 5893, 6304, 6759, 6761, 6762, 6763, 6390}) ||
-		c.Number[:3] == "0604"
-}
-
-func isDankort(ccDigits digits) bool {
-	return ccDigits.At(4) == 5019
-}
-
-func isMasterCard(ccDigits digits) bool {
-	return isInBetween(ccDigits.At(2), 51, 55) || isInBetween(ccDigits.At(6), 222100, 272099)
-}
-
-func isVisaElectron(ccDigits digits) bool {
-	return matchesValue(ccDigits.At(4), []int{4026, 4405, 4508, 4844, 4913, 4917}) || ccDigits.At(6) == 417500
-}
-
-func isVisa(ccDigits digits) bool {
-	return ccDigits.At(1) == 4
-}
-
-func isAura(ccDigits digits) bool {
-	return ccDigits.At(2) == 50
+// builtinSchemes are the schemes known to the DefaultRegistry, in the order
+// they are checked
+var builtinSchemes = []Scheme{
+	{
+		Company:   Company{"amex", "American Express"},
+		Ranges:    []IINRange{Prefix("34"), Prefix("37")},
+		Lengths:   []int{15},
+		Groups:    [][]int{{4, 6, 5}},
+		CVVLength: 4,
+		Priority:  10,
+	},
+	{
+		Company:   Company{"bankcard", "Bankcard"},
+		Ranges:    []IINRange{Prefix("5610"), PrefixRange("560221", "560225")},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  20,
+	},
+	{
+		Company: Company{"cabal", "Cabal"},
+		Ranges: []IINRange{
+			Prefix("604400"), Prefix("627170"), Prefix("603522"), Prefix("589657"),
+			PrefixRange("604201", "604219"),
+			PrefixRange("604300", "604399"),
+		},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  30,
+	},
+	{
+		Company:   Company{"china unionpay", "China UnionPay"},
+		Ranges:    []IINRange{Prefix("62"), Prefix("81")},
+		Lengths:   []int{16, 17, 18, 19},
+		CVVLength: 3,
+		Priority:  40,
+	},
+	{
+		Company:   Company{"diners club carte blanche", "Diners Club Carte Blanche"},
+		Network:   "diners club",
+		Ranges:    []IINRange{PrefixRange("300", "305").WithLength(14, 14)},
+		Lengths:   []int{14},
+		Groups:    [][]int{{4, 6, 4}},
+		CVVLength: 3,
+		Priority:  50,
+	},
+	{
+		Company:   Company{"diners club enroute", "Diners Club enRoute"},
+		Network:   "diners club",
+		Ranges:    []IINRange{Prefix("2014"), Prefix("2149")},
+		Lengths:   []int{15},
+		CVVLength: 3,
+		Priority:  60,
+	},
+	{
+		Company: Company{"diners club international", "Diners Club International"},
+		Network: "diners club",
+		Ranges: []IINRange{
+			PrefixRange("300", "305").WithLength(0, 14),
+			Prefix("309").WithLength(0, 14),
+			Prefix("36").WithLength(0, 14),
+			Prefix("38").WithLength(0, 14),
+			Prefix("39").WithLength(0, 14),
+		},
+		Lengths:   []int{14, 15, 16, 17, 18, 19},
+		Groups:    [][]int{{4, 6, 4}},
+		CVVLength: 3,
+		Priority:  70,
+	},
+	{
+		Company: Company{"discover", "Discover"},
+		Ranges: []IINRange{
+			Prefix("6011"),
+			PrefixRange("622126", "622925"),
+			PrefixRange("644", "649"),
+			Prefix("65"),
+		},
+		Lengths:   []int{16, 17, 18, 19},
+		CVVLength: 3,
+		Priority:  80,
+	},
+	{
+		Company: Company{"elo", "Elo"},
+		Ranges: []IINRange{
+			Prefix("4011"), Prefix("4576"),
+			Prefix("431274"), Prefix("438935"), Prefix("451416"), Prefix("457393"),
+			Prefix("457631"), Prefix("457632"), Prefix("504175"), Prefix("627780"),
+			Prefix("636297"), Prefix("636368"), Prefix("636369"),
+			PrefixRange("506699", "506778"),
+			PrefixRange("509000", "509999"),
+			PrefixRange("650031", "650051"),
+			PrefixRange("650405", "650439"),
+			PrefixRange("650485", "650538"),
+			PrefixRange("650541", "650598"),
+			PrefixRange("650700", "650718"),
+			PrefixRange("650720", "650727"),
+			PrefixRange("650901", "650920"),
+			PrefixRange("651652", "651679"),
+			PrefixRange("655000", "655019"),
+			PrefixRange("655021", "655021"),
+		},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  90,
+	},
+	{
+		Company: Company{"hipercard", "Hipercard"},
+		Ranges: []IINRange{
+			Prefix("606282"), Prefix("637095"), Prefix("637568"),
+			Prefix("637599"), Prefix("637609"), Prefix("637612"),
+		},
+		Lengths:   []int{16, 19},
+		CVVLength: 3,
+		Priority:  100,
+	},
+	{
+		Company:   Company{"interpayment", "InterPayment"},
+		Ranges:    []IINRange{Prefix("636").WithLength(16, 19)},
+		Lengths:   []int{16, 17, 18, 19},
+		CVVLength: 3,
+		Priority:  110,
+	},
+	{
+		Company:   Company{"instapayment", "InstaPayment"},
+		Ranges:    []IINRange{PrefixRange("637", "639").WithLength(16, 16)},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  120,
+	},
+	{
+		Company:   Company{"jcb", "JCB"},
+		Ranges:    []IINRange{PrefixRange("3528", "3589")},
+		Lengths:   []int{16, 17, 18, 19},
+		CVVLength: 3,
+		Priority:  130,
+	},
+	{
+		Company:   Company{"naranja", "Naranja"},
+		Ranges:    []IINRange{Prefix("589562")},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  140,
+	},
+	{
+		Company: Company{"maestro", "Maestro"},
+		Ranges: []IINRange{
+			Prefix("5018"), Prefix("5020"), Prefix("5038"), Prefix("5612"),
+			Prefix("5893"), Prefix("6304"), Prefix("6759"), Prefix("6761"),
+			Prefix("6762"), Prefix("6763"), Prefix("6390"), Prefix("0604"),
+		},
+		Lengths:     []int{13, 14, 15, 16, 17, 18, 19},
+		CVVLength:   3,
+		CVVOptional: true,
+		Priority:    150,
+	},
+	{
+		Company:     Company{"dankort", "Dankort"},
+		Ranges:      []IINRange{Prefix("5019"), Prefix("4571").CoBadge()},
+		Lengths:     []int{16},
+		CVVLength:   3,
+		CVVOptional: true,
+		Priority:    160,
+	},
+	{
+		Company:   Company{"mastercard", "MasterCard"},
+		Ranges:    []IINRange{PrefixRange("51", "55"), PrefixRange("222100", "272099")},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  170,
+	},
+	{
+		Company: Company{"visa electron", "Visa Electron"},
+		Network: "visa",
+		Ranges: []IINRange{
+			Prefix("4026"), Prefix("4405"), Prefix("4508"),
+			Prefix("4844"), Prefix("4913"), Prefix("4917"),
+			Prefix("417500"),
+		},
+		Lengths:   []int{16},
+		CVVLength: 3,
+		Priority:  180,
+	},
+	{
+		Company: Company{"visa", "Visa"},
+		Ranges: []IINRange{
+			Prefix("4"),
+			Prefix("4011").CoBadge(), Prefix("4576").CoBadge(),
+			Prefix("431274").CoBadge(), Prefix("438935").CoBadge(),
+			Prefix("451416").CoBadge(), Prefix("457393").CoBadge(),
+		},
+		Lengths:   []int{13, 16, 19},
+		CVVLength: 3,
+		Priority:  190,
+	},
+	{
+		Company:   Company{"aura", "Aura"},
+		Ranges:    []IINRange{Prefix("50")},
+		Lengths:   []int{16, 19},
+		CVVLength: 3,
+		Priority:  200,
+	},
 }